@@ -0,0 +1,154 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+)
+
+// ellipsoidAreaStepInKM is the maximum length of the pieces the geodesic edges of a ring are split into, when
+// calculating its area on the ellipsoid.
+const ellipsoidAreaStepInKM = 1.0
+
+// getRingVectors returns the unit vectors of the given ring vertices, leaving out nil points, repeated vertices
+// and the closing vertex.
+func getRingVectors(points []Point) []vector {
+
+	vectors := make([]vector, 0, len(points))
+
+	for _, p := range points {
+		if p != nil {
+			vectors = append(vectors, toVector(p))
+		}
+	}
+
+	return getRingVectorsOf(vectors)
+}
+
+// getSignedArea returns the signed area of the ring of the given unit vectors on the unit sphere, which is positive
+// if the ring is counter clockwise.
+// The ring is split into a fan of triangles sharing its first vertex, which keeps the precision for small rings.
+func getSignedArea(vectors []vector) float64 {
+
+	sum := 0.0
+
+	for i := 1; i+1 < len(vectors); i++ {
+		a, b, c := vectors[0], vectors[i], vectors[i+1]
+		sum += 2 * math.Atan2(a.dot(b.cross(c)), 1+a.dot(b)+b.dot(c)+c.dot(a))
+	}
+
+	return sum
+}
+
+// GetRingArea returns the signed area in square kilometers of the given ring on the sphere, where each two
+// consecutive points are connected by a great circle arc.
+// The area is positive if the ring is counter clockwise, having the area it bounds on its left side,
+// and it's negative if the ring is clockwise.
+func GetRingArea(ring []Point) float64 {
+	return getSignedArea(getRingVectors(ring)) * EarthRadiusInKM * EarthRadiusInKM
+}
+
+// authalic maps the geodetic latitudes on the WGS84 ellipsoid to the latitudes on a sphere having the same surface
+// area as the ellipsoid, keeping the area of any region the same.
+type authalic struct {
+	e      float64
+	qp     float64
+	radius float64
+}
+
+func newAuthalic() authalic {
+	f := WGS84Flattening
+	e := math.Sqrt(f * (2 - f))
+	a := authalic{e: e}
+	a.qp = a.q(1)
+	a.radius = WGS84SemiMajorAxisInKM * math.Sqrt(a.qp/2)
+	return a
+}
+
+func (a authalic) q(sinLat float64) float64 {
+	e := a.e
+	es := e * sinLat
+	return (1 - e*e) * (sinLat/(1-es*es) - math.Log((1-es)/(1+es))/(2*e))
+}
+
+// toVector returns the unit vector on the authalic sphere of the given geo-location point on the ellipsoid.
+func (a authalic) toVector(p Point) vector {
+	beta := math.Asin(math.Max(-1, math.Min(1, a.q(math.Sin(p.Latitude()*radians))/a.qp)))
+	lng := p.Longitude() * radians
+	return vector{math.Cos(beta) * math.Cos(lng), math.Cos(beta) * math.Sin(lng), math.Sin(beta)}
+}
+
+// GetRingEllipsoidArea returns the signed area in square kilometers of the given ring on the WGS84 ellipsoid,
+// where each two consecutive points are connected by a geodesic.
+// The area is positive if the ring is counter clockwise, having the area it bounds on its left side,
+// and it's negative if the ring is clockwise.
+// The edges are split into pieces of a kilometer, which are mapped to the authalic sphere having the same area as
+// the ellipsoid, making the result accurate to a few parts per billion.
+func GetRingEllipsoidArea(ring []Point) float64 {
+
+	points := make([]Point, 0, len(ring))
+
+	for _, p := range ring {
+		if p != nil {
+			points = append(points, p)
+		}
+	}
+
+	a := newAuthalic()
+	vectors := make([]vector, 0, len(points))
+
+	for i, p := range points {
+		vectors = append(vectors, a.toVector(p))
+		next := points[(i+1)%len(points)]
+		s, azimuth, ok := vincentyInverse(p, next)
+		count := int(math.Ceil(s / ellipsoidAreaStepInKM))
+
+		for j := 1; j < count; j++ {
+			var q Point
+
+			if ok {
+				q = vincentyDirect(p, azimuth, s*float64(j)/float64(count))
+			} else {
+				q = interpolate(toVector(p), toVector(next), float64(j)/float64(count)).toPoint()
+			}
+
+			vectors = append(vectors, a.toVector(q))
+		}
+	}
+
+	return getSignedArea(getRingVectorsOf(vectors)) * a.radius * a.radius
+}
+
+// getRingVectorsOf returns the given ring vectors without the repeated ones, including the closing one.
+func getRingVectorsOf(vectors []vector) []vector {
+
+	result := make([]vector, 0, len(vectors))
+
+	for _, v := range vectors {
+		if n := len(result); n > 0 && result[n-1].angle(v) < epsilon {
+			continue
+		}
+
+		result = append(result, v)
+	}
+
+	if n := len(result); n > 1 && result[0].angle(result[n-1]) < epsilon {
+		result = result[:n-1]
+	}
+
+	return result
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRingArea(t *testing.T) {
+
+	square := getSquare(0, 0, 1)
+
+	assert.InDelta(t, 12364.03, GetRingArea(square), 0.01)
+	assert.InDelta(t, -12364.03, GetRingArea(reverseRing(square)), 0.01)
+	assert.InDelta(t, GetRingArea(square), GetRingArea(square[:4]), 1e-6)
+	assert.InDelta(t, 0, GetRingArea(square[:2]), 0)
+	assert.InDelta(t, 0, GetRingArea(nil), 0)
+
+	// An octant of the sphere.
+	octant := []Point{NewPoint(0, 0), NewPoint(0, 90), NewPoint(90, 0)}
+	assert.InDelta(t, math.Pi/2*EarthRadiusInKM*EarthRadiusInKM, GetRingArea(octant), 0.01)
+
+	// The same square shifted across the antimeridian keeps its area.
+	assert.InDelta(t, GetRingArea(square), GetRingArea(getSquare(0, 179.5, 1)), 1e-6)
+}
+
+func TestGetRingEllipsoidArea(t *testing.T) {
+
+	square := getSquare(0, 0, 1)
+
+	assert.InDelta(t, 12308.778, GetRingEllipsoidArea(square), 0.001)
+	assert.InDelta(t, -12308.778, GetRingEllipsoidArea(reverseRing(square)), 0.001)
+	assert.InDelta(t, GetRingEllipsoidArea(square), GetRingEllipsoidArea(getSquare(0, 179.5, 1)), 1e-6)
+
+	// A ring around the north pole, the surface of earth beyond 80 degrees north is about 3.7 million square
+	// kilometers.
+	polar := []Point{}
+	for lng := -180.0; lng < 180; lng += 30 {
+		polar = append(polar, NewPoint(80, lng))
+	}
+
+	assert.InDelta(t, 3.736e6, GetRingEllipsoidArea(polar), 1e3)
+	assert.InDelta(t, -3.736e6, GetRingEllipsoidArea(reverseRing(polar)), 1e3)
+}
+
+func TestPolygon_Area(t *testing.T) {
+
+	p := NewPolygon(getSquare(0, 0, 10), reverseRing(getSquare(4, 4, 2)), getSquare(1, 1, 1))
+	expected := GetRingArea(getSquare(0, 0, 10)) - GetRingArea(getSquare(4, 4, 2)) - GetRingArea(getSquare(1, 1, 1))
+
+	assert.InDelta(t, expected, p.Area(), 1e-6)
+	assert.True(t, p.EllipsoidArea() < p.Area())
+
+	m := NewMultiPolygon(p, NewPolygon(reverseRing(getSquare(0, 179.5, 1))))
+	assert.InDelta(t, expected+GetRingArea(getSquare(0, 0, 1)), m.Area(), 1e-6)
+	assert.InDelta(t, p.EllipsoidArea()+GetRingEllipsoidArea(getSquare(0, 0, 1)), m.EllipsoidArea(), 1e-6)
+}
+
+func TestPolygon_Perimeter(t *testing.T) {
+
+	p := NewPolygon(getSquare(0, 0, 1)[:4], getSquare(0.25, 0.25, 0.5))
+
+	assert.InDelta(t, 6*111.195, p.Perimeter(), 0.1)
+	assert.InDelta(t, 6*111, p.EllipsoidPerimeter(), 1)
+
+	m := NewMultiPolygon(p, p)
+	assert.InDelta(t, 2*p.Perimeter(), m.Perimeter(), 1e-9)
+	assert.InDelta(t, 2*p.EllipsoidPerimeter(), m.EllipsoidPerimeter(), 1e-9)
+}
@@ -19,6 +19,7 @@ package geo
 import (
 	"fmt"
 	"math"
+	"sort"
 )
 
 // Boundary represents a predefined geo-location polygon identified by two points.
@@ -92,3 +93,133 @@ func NewBoundary(lower Point, upper Point) Boundary {
 
 	return &boundary{lower: <-chLower, upper: <-chUpper}
 }
+
+// getPointsBoundary returns the smallest boundary containing all the given geo-location points.
+// The longitude range is picked as the complement of the widest longitude gap between the points, so points
+// around the antimeridian result in a boundary crossing it instead of one going around the whole earth.
+func getPointsBoundary(points []Point) Boundary {
+
+	minLat := NorthPoleLat
+	maxLat := SouthPoleLat
+	lngs := make([]float64, 0, len(points))
+
+	for _, p := range points {
+		if p == nil {
+			continue
+		}
+
+		minLat = math.Min(minLat, p.Latitude())
+		maxLat = math.Max(maxLat, p.Latitude())
+
+		// The longitude has no meaning at the poles.
+		if math.Abs(p.Latitude()) != NorthPoleLat {
+			lngs = append(lngs, p.Longitude())
+		}
+	}
+
+	if minLat > maxLat {
+		return nil
+	}
+
+	if len(lngs) == 0 {
+		return NewBoundary(NewPoint(minLat, 0), NewPoint(maxLat, 0))
+	}
+
+	sort.Float64s(lngs)
+
+	// Starting with the gap crossing the antimeridian, from the most eastern point to the most western one.
+	lowerLng := lngs[0]
+	upperLng := lngs[len(lngs)-1]
+	gap := lngs[0] + TotalLongitude - lngs[len(lngs)-1]
+
+	for i := 1; i < len(lngs); i++ {
+		if lngs[i]-lngs[i-1] > gap {
+			gap = lngs[i] - lngs[i-1]
+			lowerLng = lngs[i]
+			upperLng = lngs[i-1]
+		}
+	}
+
+	return NewBoundary(NewPoint(minLat, lowerLng), NewPoint(maxLat, upperLng))
+}
+
+// getLineBoundary returns the smallest boundary containing the line going through the given geo-location points,
+// taking into account that the great circle arcs between the points may bulge beyond their latitudes.
+func getLineBoundary(points []Point) Boundary {
+	return getPointsBoundary(getLineExtremes(points))
+}
+
+// getLineExtremes returns the given geo-location points along with the most northern and southern points of the
+// great circle arcs between them, which are enough to find the boundary of the line going through them.
+func getLineExtremes(points []Point) []Point {
+
+	all := make([]Point, 0, len(points))
+	north := vector{0, 0, 1}
+	south := vector{0, 0, -1}
+
+	for i, p := range points {
+		all = append(all, p)
+
+		if i > 0 {
+			a, b := toVector(points[i-1]), toVector(p)
+
+			// The points of the arc closest to the poles are its most northern and southern points.
+			for _, pole := range []vector{north, south} {
+				if v, f := closestOnArc(pole, a, b); f > 0 && f < 1 {
+					all = append(all, v.toPoint())
+				}
+			}
+		}
+	}
+
+	return all
+}
+
+// getBoundaryLngRange returns the longitude range of the given boundary, where the upper longitude is pushed
+// beyond +180 if the boundary crosses the antimeridian, so that it's never less than the lower longitude.
+// A boundary touching a pole with no longitude range, as created by NewBoundary, covers all the longitudes.
+func getBoundaryLngRange(b Boundary) (float64, float64) {
+
+	lower, upper := b.Lower(), b.Upper()
+	minLng, maxLng := lower.Longitude(), upper.Longitude()
+
+	touchesPole := math.Abs(lower.Latitude()) == NorthPoleLat || math.Abs(upper.Latitude()) == NorthPoleLat
+
+	if touchesPole && minLng == maxLng {
+		return -HalfLongitude, HalfLongitude
+	}
+
+	if maxLng < minLng {
+		maxLng += TotalLongitude
+	}
+
+	return minLng, maxLng
+}
+
+// boundaryContains reports whether the given geo-location point is inside the given boundary, including its edges.
+func boundaryContains(b Boundary, p Point) bool {
+
+	if b == nil || p == nil {
+		return false
+	}
+
+	lat := p.Latitude()
+
+	if lat < b.Lower().Latitude() || lat > b.Upper().Latitude() {
+		return false
+	}
+
+	// The longitude has no meaning at the poles.
+	if math.Abs(lat) == NorthPoleLat {
+		return true
+	}
+
+	minLng, maxLng := getBoundaryLngRange(b)
+	lng := p.Longitude()
+
+	if lng < minLng {
+		lng += TotalLongitude
+	}
+
+	return lng <= maxLng
+}
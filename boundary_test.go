@@ -87,3 +87,39 @@ func TestBoundary_ConstructorAndGettersWithNonNormalizedPoints(t *testing.T) {
 	assert.InDelta(t, 2, b.Upper().Latitude(), 0)
 	assert.InDelta(t, 0, b.Upper().Longitude(), 0)
 }
+
+func TestGetPointsBoundary(t *testing.T) {
+
+	assert.Nil(t, getPointsBoundary(nil))
+
+	b := getPointsBoundary([]Point{NewPoint(1, 2), NewPoint(-3, 4), NewPoint(5, -6)})
+	assert.InDelta(t, -3, b.Lower().Latitude(), 0)
+	assert.InDelta(t, -6, b.Lower().Longitude(), 0)
+	assert.InDelta(t, 5, b.Upper().Latitude(), 0)
+	assert.InDelta(t, 4, b.Upper().Longitude(), 0)
+
+	b = getPointsBoundary([]Point{NewPoint(1, 170), NewPoint(-3, -175), NewPoint(5, 179)})
+	assert.InDelta(t, -3, b.Lower().Latitude(), 0)
+	assert.InDelta(t, 170, b.Lower().Longitude(), 0)
+	assert.InDelta(t, 5, b.Upper().Latitude(), 0)
+	assert.InDelta(t, -175, b.Upper().Longitude(), 0)
+
+	b = getPointsBoundary([]Point{NewPoint(90, 0), NewPoint(80, 30)})
+	assert.InDelta(t, 80, b.Lower().Latitude(), 0)
+	assert.InDelta(t, 90, b.Upper().Latitude(), 0)
+}
+
+func TestBoundaryContains(t *testing.T) {
+
+	b := NewBoundary(NewPoint(-1, 179), NewPoint(1, -179))
+	assert.True(t, boundaryContains(b, NewPoint(0, 180)))
+	assert.True(t, boundaryContains(b, NewPoint(0, -179.5)))
+	assert.True(t, boundaryContains(b, NewPoint(1, 179)))
+	assert.False(t, boundaryContains(b, NewPoint(0, 0)))
+	assert.False(t, boundaryContains(b, NewPoint(2, 180)))
+	assert.False(t, boundaryContains(nil, NewPoint(0, 0)))
+
+	b = NewBoundary(NewPoint(80, 10), NewPoint(90, 20))
+	assert.True(t, boundaryContains(b, NewPoint(85, -100)))
+	assert.True(t, boundaryContains(b, NewPoint(90, 0)))
+}
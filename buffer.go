@@ -0,0 +1,289 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"errors"
+	"math"
+)
+
+// CapStyle is the shape of the ends of a line buffer.
+type CapStyle byte
+
+const (
+	// RoundCap ends a line buffer with a half circle around each end of the line.
+	RoundCap CapStyle = iota
+	// FlatCap ends a line buffer with a straight edge through each end of the line.
+	FlatCap
+)
+
+// DefaultBufferSegments is the number of segments approximating a full circle in buffers, used when fewer than
+// three segments are given.
+const DefaultBufferSegments = 32
+
+// ErrInvalidBufferDistance is returned when a buffer distance is not usable, such as a line buffer with a
+// distance that is not positive, or any buffer reaching a quarter of a great circle.
+var ErrInvalidBufferDistance = errors.New("invalid buffer distance")
+
+// bufferStepAngle is the largest angle in radians between two consecutive vertices along the sides of a line
+// buffer, which follow small circles rather than great circles.
+const bufferStepAngle = radians
+
+// getBufferSegments returns the given number of segments of a full circle, or the default one if it's too small.
+func getBufferSegments(segments int) int {
+	if segments < 3 {
+		return DefaultBufferSegments
+	}
+	return segments
+}
+
+// getBufferAngle converts the given buffer distance in kilometers to a central angle in radians, returning
+// ErrInvalidBufferDistance if its absolute value reaches a quarter of a great circle.
+func getBufferAngle(distanceInKM float64) (float64, error) {
+
+	angle := kmToAngle(distanceInKM)
+
+	if math.IsNaN(angle) || math.Abs(angle) >= math.Pi/2 {
+		return 0, ErrInvalidBufferDistance
+	}
+
+	return angle, nil
+}
+
+// offset returns the unit vector at the given angular distance from v, in the direction making the given angle
+// from the tangent t towards the tangent n, both being perpendicular unit vectors.
+func offset(v, t, n vector, theta, distance float64) vector {
+	return v.scale(math.Cos(distance)).add(t.scale(math.Cos(theta) * math.Sin(distance))).
+		add(n.scale(math.Sin(theta) * math.Sin(distance))).normalize()
+}
+
+// getArcBuffer returns the ring of the buffer around the great circle arc going from a to b, made of both sides
+// of the arc, and a half circle at each of its ends unless the end is flat.
+func getArcBuffer(a, b vector, distance float64, segments int, flatStart, flatEnd bool) []Point {
+
+	n := a.cross(b).normalize()
+	steps := int(math.Max(1, math.Ceil(a.angle(b)/bufferStepAngle)))
+	half := segments / 2
+	points := []Point{}
+
+	// side adds the points along the side of the arc at the given signed distance, which is positive on the
+	// left side, going from a to b if forward is true.
+	side := func(distance float64, forward bool) {
+		for i := 0; i <= steps; i++ {
+			f := float64(i) / float64(steps)
+
+			if !forward {
+				f = 1 - f
+			}
+
+			v := interpolate(a, b, f)
+			points = append(points, v.scale(math.Cos(distance)).add(n.scale(math.Sin(distance))).normalize().toPoint())
+		}
+	}
+
+	// end adds the points of the half circle around v going counter clockwise from the right side of the tangent
+	// t to its left side, excluding both of them which are on the arc sides.
+	end := func(v, t vector, flat bool) {
+		if flat {
+			return
+		}
+
+		for i := 1; i < half; i++ {
+			theta := -math.Pi/2 + math.Pi*float64(i)/float64(half)
+			points = append(points, offset(v, t, v.cross(t), theta, distance).toPoint())
+		}
+	}
+
+	side(-distance, true)
+	end(b, direction(b, a).scale(-1), flatEnd)
+	side(distance, false)
+	end(a, direction(a, b).scale(-1), flatStart)
+
+	return closeRing(points)
+}
+
+// getBufferUnion returns the union of the given areas, merging them in pairs to keep the merged areas small.
+func getBufferUnion(parts []MultiPolygon) (MultiPolygon, error) {
+
+	if len(parts) == 0 {
+		return NewMultiPolygon(), nil
+	}
+
+	for len(parts) > 1 {
+		merged := make([]MultiPolygon, 0, (len(parts)+1)/2)
+
+		for i := 0; i < len(parts); i += 2 {
+			if i+1 == len(parts) {
+				merged = append(merged, parts[i])
+				continue
+			}
+
+			m, err := GetUnion(parts[i], parts[i+1])
+
+			if err != nil {
+				return nil, err
+			}
+
+			merged = append(merged, m)
+		}
+
+		parts = merged
+	}
+
+	return parts[0], nil
+}
+
+// GetPointBuffer returns the polygon approximating the circle of the given radius in kilometers around the given
+// point on the sphere, with the given number of segments, or nil if the point is nil or the radius is not valid.
+// The polygon vertices are on the circle, starting due north of the center and going counter clockwise, as
+// ValidatePolygon expects of a shell.
+func GetPointBuffer(p Point, radiusInKM float64, segments int) Polygon {
+
+	if p == nil || radiusInKM <= 0 {
+		return nil
+	}
+
+	distance, err := getBufferAngle(radiusInKM)
+
+	if err != nil {
+		return nil
+	}
+
+	segments = getBufferSegments(segments)
+	v := toVector(p)
+	north := direction(v, vector{0, 0, 1})
+
+	if north.norm() < epsilon {
+		// At the poles, any direction would do.
+		north = v.perpendicular()
+	}
+
+	west := v.cross(north)
+	points := make([]Point, 0, segments+1)
+
+	for i := 0; i < segments; i++ {
+		theta := 2 * math.Pi * float64(i) / float64(segments)
+		points = append(points, offset(v, north, west, theta, distance).toPoint())
+	}
+
+	return NewPolygon(closeRing(points))
+}
+
+// GetLineBuffer returns the area within the given distance in kilometers from the line going through the given
+// points on the sphere, where the corners of the line are rounded, and its ends are either rounded or flat
+// depending on the given cap style.
+// The given number of segments is for a full circle, and the sides of the buffer follow the line at a constant
+// distance rather than a great circle.
+// A line of a single point is buffered as a point, regardless of the cap style.
+// The distance has to be positive, otherwise ErrInvalidBufferDistance is returned, while ErrNotInHemisphere is
+// returned if the buffer doesn't fit in a single hemisphere.
+func GetLineBuffer(points []Point, distanceInKM float64, segments int, style CapStyle) (MultiPolygon, error) {
+
+	if distanceInKM <= 0 {
+		return nil, ErrInvalidBufferDistance
+	}
+
+	distance, err := getBufferAngle(distanceInKM)
+
+	if err != nil {
+		return nil, err
+	}
+
+	segments = getBufferSegments(segments)
+	vectors := []vector{}
+	first := Point(nil)
+
+	for _, p := range points {
+		if p == nil {
+			continue
+		}
+
+		if first == nil {
+			first = p
+		}
+
+		v := toVector(p)
+
+		if n := len(vectors); n == 0 || vectors[n-1].angle(v) >= epsilon {
+			vectors = append(vectors, v)
+		}
+	}
+
+	switch len(vectors) {
+	case 0:
+		return nil, ErrEmptyPointSet
+	case 1:
+		return NewMultiPolygon(GetPointBuffer(first, distanceInKM, segments)), nil
+	}
+
+	parts := []MultiPolygon{}
+	last := len(vectors) - 2
+
+	for i := 0; i <= last; i++ {
+		flatStart := style == FlatCap && i == 0
+		flatEnd := style == FlatCap && i == last
+		ring := getArcBuffer(vectors[i], vectors[i+1], distance, segments, flatStart, flatEnd)
+		parts = append(parts, NewMultiPolygon(NewPolygon(ring)))
+	}
+
+	return getBufferUnion(parts)
+}
+
+// GetPolygonBuffer returns the given polygon grown by the given distance in kilometers if it's positive, or
+// shrunk by it if it's negative, on the sphere, where the grown corners are rounded.
+// The given number of segments is for a full circle, ErrNotInHemisphere is returned if the result doesn't fit
+// in a single hemisphere.
+func GetPolygonBuffer(p Polygon, distanceInKM float64, segments int) (MultiPolygon, error) {
+
+	if p == nil {
+		return nil, ErrEmptyPointSet
+	}
+
+	if distanceInKM == 0 {
+		return NewMultiPolygon(p), nil
+	}
+
+	if _, err := getBufferAngle(distanceInKM); err != nil {
+		return nil, err
+	}
+
+	// The area within the distance from the polygon rings is added to the polygon when growing, or removed
+	// from it when shrinking.
+	parts := []MultiPolygon{}
+
+	for _, r := range append([][]Point{p.Shell()}, p.Holes()...) {
+		corridor, err := GetLineBuffer(closeRing(r), math.Abs(distanceInKM), segments, RoundCap)
+
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, corridor)
+	}
+
+	corridors, err := getBufferUnion(parts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if distanceInKM > 0 {
+		return GetUnion(NewMultiPolygon(p), corridors)
+	}
+
+	return GetDifference(NewMultiPolygon(p), corridors)
+}
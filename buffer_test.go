@@ -0,0 +1,146 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPointBuffer(t *testing.T) {
+
+	assert.Nil(t, GetPointBuffer(nil, 1, 8))
+	assert.Nil(t, GetPointBuffer(NewPoint(0, 0), 0, 8))
+	assert.Nil(t, GetPointBuffer(NewPoint(0, 0), EarthRadiusInKM*2, 8))
+
+	center := NewPoint(45, 179.99)
+	p := GetPointBuffer(center, 10, 64)
+	assert.Len(t, p.Shell(), 65)
+	assert.True(t, p.Contains(center))
+	assert.True(t, p.Contains(NewPoint(45, -179.99)))
+	assert.False(t, p.Contains(NewPoint(45.1, 179.99)))
+	assert.InDelta(t, math.Pi*100, p.Area(), 1)
+
+	for _, v := range p.Shell() {
+		assert.InDelta(t, 10, angleToKM(toVector(center).angle(toVector(v))), 1e-3)
+	}
+
+	// The shell is counter clockwise, starting due north of the center and heading west.
+	assert.Empty(t, ValidatePolygon(p))
+	assert.True(t, getSignedArea(getRingVectors(p.Shell())) > 0)
+	assert.InDelta(t, 45.09, p.Shell()[0].Latitude(), 1e-2)
+	assert.True(t, p.Shell()[1].Longitude() < 179.99)
+
+	// The default number of segments is used when too few are given.
+	assert.Len(t, GetPointBuffer(center, 10, 2).Shell(), DefaultBufferSegments+1)
+
+	p = GetPointBuffer(NewPoint(90, 0), 100, 16)
+	assert.True(t, p.Contains(NewPoint(90, 0)))
+	assert.True(t, p.Contains(NewPoint(89.5, -120)))
+	assert.False(t, p.Contains(NewPoint(89, 60)))
+	assert.Empty(t, ValidatePolygon(p))
+}
+
+func TestGetLineBuffer(t *testing.T) {
+
+	line := []Point{NewPoint(0, 0), NewPoint(0, 1)}
+	length := angleToKM(toVector(line[0]).angle(toVector(line[1])))
+
+	round, err := GetLineBuffer(line, 10, 64, RoundCap)
+	assert.Nil(t, err)
+	assert.Len(t, round.Polygons(), 1)
+	assert.InDelta(t, 2*10*length+math.Pi*100, round.Area(), 2)
+	assert.True(t, round.Contains(NewPoint(0, -0.05)))
+	assert.True(t, round.Contains(NewPoint(0.08, 0.5)))
+	assert.False(t, round.Contains(NewPoint(0.1, 0.5)))
+
+	flat, err := GetLineBuffer(line, 10, 64, FlatCap)
+	assert.Nil(t, err)
+	assert.InDelta(t, 2*10*length, flat.Area(), 2)
+	assert.False(t, flat.Contains(NewPoint(0, -0.05)))
+
+	// A track turning back on itself is covered once.
+	track := []Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1), NewPoint(1, 0), NewPoint(0, 0), NewPoint(0.5, 0.5)}
+	m, err := GetLineBuffer(track, 5, 16, RoundCap)
+	assert.Nil(t, err)
+	assert.Len(t, m.Polygons(), 1)
+	assert.Len(t, m.Polygons()[0].Holes(), 1)
+	assert.Empty(t, ValidatePolygon(m.Polygons()[0]))
+	assert.True(t, m.Contains(NewPoint(0.5, 0.5)))
+	assert.False(t, m.Contains(NewPoint(0.25, 0.75)))
+
+	// Across the antimeridian.
+	m, err = GetLineBuffer([]Point{NewPoint(10, 179.5), nil, NewPoint(10, -179.5)}, 1, 16, FlatCap)
+	assert.Nil(t, err)
+	assert.True(t, m.Contains(NewPoint(10, 180)))
+	assert.False(t, m.Contains(NewPoint(10, 0)))
+
+	m, err = GetLineBuffer([]Point{NewPoint(1, 1), NewPoint(1, 1)}, 1, 16, FlatCap)
+	assert.Nil(t, err)
+	assert.InDelta(t, math.Pi, m.Area(), 0.1)
+
+	_, err = GetLineBuffer(line, 0, 16, RoundCap)
+	assert.Equal(t, ErrInvalidBufferDistance, err)
+
+	_, err = GetLineBuffer(nil, 1, 16, RoundCap)
+	assert.Equal(t, ErrEmptyPointSet, err)
+}
+
+func TestGetPolygonBuffer(t *testing.T) {
+
+	square := NewPolygon(getSquare(0, 0, 1))
+	side := angleToKM(toVector(NewPoint(0, 0)).angle(toVector(NewPoint(0, 1))))
+
+	grown, err := GetPolygonBuffer(square, 10, 64)
+	assert.Nil(t, err)
+	assert.Len(t, grown.Polygons(), 1)
+	assert.Empty(t, grown.Polygons()[0].Holes())
+	assert.InDelta(t, square.Area()+4*10*side+math.Pi*100, grown.Area(), 10)
+	assert.True(t, grown.Contains(NewPoint(-0.05, 0.5)))
+	assert.False(t, grown.Contains(NewPoint(-0.1, 0.5)))
+
+	shrunk, err := GetPolygonBuffer(square, -10, 64)
+	assert.Nil(t, err)
+	assert.Len(t, shrunk.Polygons(), 1)
+	assert.InDelta(t, (side-20)*(side-20), shrunk.Area(), 10)
+	assert.False(t, shrunk.Contains(NewPoint(0.05, 0.5)))
+	assert.True(t, shrunk.Contains(NewPoint(0.5, 0.5)))
+
+	// Shrinking by more than half the width leaves nothing.
+	shrunk, err = GetPolygonBuffer(square, -60, 16)
+	assert.Nil(t, err)
+	assert.Empty(t, shrunk.Polygons())
+
+	// Growing a polygon closes its small holes.
+	holed := NewPolygon(getSquare(0, 0, 1), getSquare(0.4, 0.4, 0.1))
+	grown, err = GetPolygonBuffer(holed, 10, 16)
+	assert.Nil(t, err)
+	assert.Len(t, grown.Polygons(), 1)
+	assert.Empty(t, grown.Polygons()[0].Holes())
+
+	same, err := GetPolygonBuffer(square, 0, 16)
+	assert.Nil(t, err)
+	assert.Equal(t, square, same.Polygons()[0])
+
+	_, err = GetPolygonBuffer(nil, 1, 16)
+	assert.Equal(t, ErrEmptyPointSet, err)
+
+	_, err = GetPolygonBuffer(square, EarthRadiusInKM*2, 16)
+	assert.Equal(t, ErrInvalidBufferDistance, err)
+}
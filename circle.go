@@ -0,0 +1,170 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Circle is a spherical cap on the surface of earth, made of all the geo-location points within a given distance
+// from its center.
+type Circle interface {
+	// Center returns the center of the circle.
+	Center() Point
+	// Radius returns the radius of the circle in kilometers along the surface of earth.
+	Radius() float64
+	// Contains reports whether the given point is inside the circle, including its edge.
+	Contains(point Point) bool
+	// Intersects reports whether the circle and the given boundary have any point in common.
+	Intersects(b Boundary) bool
+	// Boundary returns the smallest boundary containing the circle, which covers all the longitudes if the
+	// circle contains a pole, and crosses the antimeridian if the circle does.
+	Boundary() Boundary
+	// Polygon returns the polygon approximating the circle with the given number of segments.
+	Polygon(segments int) Polygon
+}
+
+type circle struct {
+	center Point
+	radius float64
+	vector vector
+	angle  float64
+}
+
+func (c *circle) String() string {
+	return fmt.Sprintf("(%v, %vkm)", c.Center(), c.Radius())
+}
+
+func (c *circle) Center() Point {
+	if c != nil {
+		return c.center
+	}
+	return nil
+}
+
+func (c *circle) Radius() float64 {
+	if c != nil {
+		return c.radius
+	}
+	return 0.0
+}
+
+func (c *circle) Contains(point Point) bool {
+
+	if c == nil || point == nil {
+		return false
+	}
+
+	return c.vector.angle(toVector(point)) <= c.angle
+}
+
+func (c *circle) Intersects(b Boundary) bool {
+
+	if c == nil || b == nil {
+		return false
+	}
+
+	if boundaryContains(b, c.center) {
+		return true
+	}
+
+	// Otherwise, the circle intersects the boundary only if it reaches one of its edges.
+	minLat, maxLat := b.Lower().Latitude(), b.Upper().Latitude()
+	minLng, maxLng := getBoundaryLngRange(b)
+
+	// The closest points to the center on the parallel edges are on the center meridian if the edges reach it,
+	// otherwise they are at the nearest ends of the edges.
+	lng := c.center.Longitude()
+
+	if lng < minLng {
+		lng += TotalLongitude
+	}
+
+	if lng > maxLng {
+		if lng-maxLng < minLng+TotalLongitude-lng {
+			lng = maxLng
+		} else {
+			lng = minLng
+		}
+	}
+
+	for _, lat := range []float64{minLat, maxLat} {
+		if c.Contains(NewPoint(lat, lng)) {
+			return true
+		}
+	}
+
+	if maxLng-minLng >= TotalLongitude {
+		return false
+	}
+
+	for _, lng := range []float64{minLng, maxLng} {
+		closest, _ := closestOnArc(c.vector, toVector(NewPoint(minLat, lng)), toVector(NewPoint(maxLat, lng)))
+
+		if c.vector.angle(closest) <= c.angle {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *circle) Boundary() Boundary {
+
+	if c == nil {
+		return nil
+	}
+
+	minLat, maxLat, minLng, maxLng := getCapRange(c.center, c.angle)
+
+	if maxLng-minLng >= TotalLongitude {
+		// Going through NewPoint would turn -180 into +180, losing the full longitude range.
+		return &boundary{
+			lower: &point{latitude: minLat, longitude: -HalfLongitude},
+			upper: &point{latitude: maxLat, longitude: HalfLongitude},
+		}
+	}
+
+	return &boundary{lower: NewPoint(minLat, minLng), upper: NewPoint(maxLat, maxLng)}
+}
+
+func (c *circle) Polygon(segments int) Polygon {
+
+	if c == nil {
+		return nil
+	}
+
+	return GetPointBuffer(c.center, c.radius, segments)
+}
+
+// NewCircle creates a new circle instance, given its center and its radius in kilometers, or returns nil if
+// the center is nil or the radius is negative.
+// A radius beyond half the circumference of earth makes a circle covering the whole surface.
+func NewCircle(center Point, radiusInKM float64) Circle {
+
+	if center == nil || radiusInKM < 0 || math.IsNaN(radiusInKM) {
+		return nil
+	}
+
+	return &circle{
+		center: center,
+		radius: radiusInKM,
+		vector: toVector(center),
+		angle:  math.Min(kmToAngle(radiusInKM), math.Pi),
+	}
+}
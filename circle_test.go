@@ -0,0 +1,135 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircle_Nil(t *testing.T) {
+
+	var c *circle
+	var cl Circle = c
+
+	assert.Nil(t, cl.Center())
+	assert.Equal(t, 0.0, cl.Radius())
+	assert.False(t, cl.Contains(NewPoint(0, 0)))
+	assert.False(t, cl.Intersects(NewBoundary(NewPoint(0, 0), NewPoint(1, 1))))
+	assert.Nil(t, cl.Boundary())
+	assert.Nil(t, cl.Polygon(8))
+
+	assert.Nil(t, NewCircle(nil, 1))
+	assert.Nil(t, NewCircle(NewPoint(0, 0), -1))
+}
+
+func TestCircle_Contains(t *testing.T) {
+
+	c := NewCircle(NewPoint(0, 179.9), 20)
+	assert.Equal(t, 20.0, c.Radius())
+	assert.Equal(t, NewPoint(0, 179.9), c.Center())
+	assert.True(t, c.Contains(NewPoint(0, 179.9)))
+	assert.True(t, c.Contains(NewPoint(0, -179.95)))
+	assert.False(t, c.Contains(NewPoint(0, -179.7)))
+	assert.False(t, c.Contains(nil))
+
+	c = NewCircle(NewPoint(89.9, 0), 30)
+	assert.True(t, c.Contains(NewPoint(89.9, 180)))
+	assert.True(t, c.Contains(NewPoint(90, 0)))
+	assert.False(t, c.Contains(NewPoint(89.5, 0)))
+
+	c = NewCircle(NewPoint(10, 10), EarthRadiusInKM*4)
+	assert.True(t, c.Contains(NewPoint(-10, -170)))
+}
+
+func TestCircle_Edge(t *testing.T) {
+
+	c := NewCircle(NewPoint(10, 10), 100)
+
+	// Just inside the edge due north, 99.99km away on the sphere, but 100.03km by GetDistance.
+	inside := NewPoint(10+kmToAngle(99.99)/radians, 10)
+	assert.True(t, c.Contains(inside))
+	assert.InDelta(t, 100.03, GetDistance(c.Center(), inside), 1e-2)
+
+	// Just outside the edge, 100.01km away on the sphere.
+	outside := NewPoint(10+kmToAngle(100.01)/radians, 10)
+	assert.False(t, c.Contains(outside))
+	assert.True(t, GetDistance(c.Center(), outside) > 100)
+
+	// Both agree away from the edge.
+	for _, km := range []float64{0, 50, 99.9, 100.1, 150} {
+		p := NewPoint(10+kmToAngle(km)/radians, 10)
+		assert.Equal(t, GetDistance(c.Center(), p) <= 100, c.Contains(p), "%vkm", km)
+	}
+}
+
+func TestCircle_Boundary(t *testing.T) {
+
+	// Crossing the antimeridian.
+	b := NewCircle(NewPoint(0, 179.9), 20).Boundary()
+	d := 20 / EarthRadiusInKM / radians
+	assert.InDelta(t, -d, b.Lower().Latitude(), DecimalPrecision)
+	assert.InDelta(t, d, b.Upper().Latitude(), DecimalPrecision)
+	assert.InDelta(t, 179.9-d, b.Lower().Longitude(), DecimalPrecision)
+	assert.InDelta(t, -179.9+d-0.2, b.Upper().Longitude(), DecimalPrecision)
+
+	// The meridians tangent to the circle are wider apart than its width along its center parallel.
+	b = NewCircle(NewPoint(60, 0), 100).Boundary()
+	assert.True(t, b.Upper().Longitude() > 2*100/EarthRadiusInKM/radians)
+
+	// Containing a pole.
+	b = NewCircle(NewPoint(-89.9, 45), 30).Boundary()
+	assert.Equal(t, SouthPoleLat, b.Lower().Latitude())
+	assert.Equal(t, -HalfLongitude, b.Lower().Longitude())
+	assert.Equal(t, HalfLongitude, b.Upper().Longitude())
+	assert.True(t, boundaryContains(b, NewPoint(-89.8, -180)))
+}
+
+func TestCircle_Intersects(t *testing.T) {
+
+	c := NewCircle(NewPoint(0, 0), 200)
+
+	// The center inside the boundary.
+	assert.True(t, c.Intersects(NewBoundary(NewPoint(-1, -1), NewPoint(1, 1))))
+	// Reaching a parallel edge.
+	assert.True(t, c.Intersects(NewBoundary(NewPoint(1, -5), NewPoint(3, 5))))
+	assert.False(t, c.Intersects(NewBoundary(NewPoint(2, -5), NewPoint(3, 5))))
+	// Reaching a meridian edge.
+	assert.True(t, c.Intersects(NewBoundary(NewPoint(-5, 1), NewPoint(5, 3))))
+	assert.False(t, c.Intersects(NewBoundary(NewPoint(-5, 2), NewPoint(5, 3))))
+	// Reaching a corner only.
+	assert.True(t, c.Intersects(NewBoundary(NewPoint(1.2, 1.2), NewPoint(3, 3))))
+	assert.False(t, c.Intersects(NewBoundary(NewPoint(1.4, 1.4), NewPoint(3, 3))))
+	// Across the antimeridian.
+	c = NewCircle(NewPoint(0, -179.5), 100)
+	assert.True(t, c.Intersects(NewBoundary(NewPoint(-1, 179), NewPoint(1, 179.9))))
+	assert.False(t, c.Intersects(NewBoundary(NewPoint(-1, 170), NewPoint(1, 178))))
+	// A boundary around a pole.
+	c = NewCircle(NewPoint(88, 100), 300)
+	assert.True(t, c.Intersects(NewBoundary(NewPoint(89, 0), NewPoint(90, 0))))
+	assert.False(t, c.Intersects(nil))
+}
+
+func TestCircle_Polygon(t *testing.T) {
+
+	c := NewCircle(NewPoint(10, 10), 5)
+	p := c.Polygon(16)
+	assert.Len(t, p.Shell(), 17)
+	assert.True(t, p.Contains(c.Center()))
+	assert.Empty(t, ValidatePolygon(p))
+}
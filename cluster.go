@@ -0,0 +1,144 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+// Noise is the label given to the geo-location points that don't belong to any cluster.
+const Noise = -1
+
+// Cluster represents a group of geo-location points found by one of the clustering algorithms.
+type Cluster interface {
+	// Indices returns the indices of the cluster points in the slice given to the clustering algorithm.
+	Indices() []int
+	// Centroid returns the spherical centroid of the cluster points.
+	Centroid() Point
+	// Boundary returns the smallest boundary containing all the cluster points.
+	Boundary() Boundary
+}
+
+// Clustering represents the result of one of the clustering algorithms.
+type Clustering interface {
+	// Labels returns the cluster index of each of the points given to the clustering algorithm,
+	// in the same order, or Noise if the point doesn't belong to any cluster.
+	Labels() []int
+	// Clusters returns the clusters found, each cluster is at the index used as its label.
+	Clusters() []Cluster
+	// Noise returns the indices of the points that don't belong to any cluster.
+	Noise() []int
+}
+
+type cluster struct {
+	indices  []int
+	centroid Point
+	boundary Boundary
+}
+
+func (c *cluster) Indices() []int {
+	if c != nil {
+		return c.indices
+	}
+	return nil
+}
+
+func (c *cluster) Centroid() Point {
+	if c != nil {
+		return c.centroid
+	}
+	return nil
+}
+
+func (c *cluster) Boundary() Boundary {
+	if c != nil {
+		return c.boundary
+	}
+	return nil
+}
+
+type clustering struct {
+	labels   []int
+	clusters []Cluster
+	noise    []int
+}
+
+func (c *clustering) Labels() []int {
+	if c != nil {
+		return c.labels
+	}
+	return nil
+}
+
+func (c *clustering) Clusters() []Cluster {
+	if c != nil {
+		return c.clusters
+	}
+	return nil
+}
+
+func (c *clustering) Noise() []int {
+	if c != nil {
+		return c.noise
+	}
+	return nil
+}
+
+// newClustering builds the clustering result out of the given points and their labels.
+func newClustering(points []Point, labels []int, count int) *clustering {
+
+	members := make([][]Point, count)
+	indices := make([][]int, count)
+	noise := []int{}
+
+	for i, label := range labels {
+		if label == Noise {
+			noise = append(noise, i)
+			continue
+		}
+
+		members[label] = append(members[label], points[i])
+		indices[label] = append(indices[label], i)
+	}
+
+	clusters := make([]Cluster, count)
+
+	for i := range clusters {
+		clusters[i] = &cluster{
+			indices:  indices[i],
+			centroid: getCentroid(members[i]),
+			boundary: getPointsBoundary(members[i]),
+		}
+	}
+
+	return &clustering{labels: labels, clusters: clusters, noise: noise}
+}
+
+// getCentroid returns the spherical centroid of the given geo-location points, which is the point the sum of
+// their unit vectors is pointing at.
+func getCentroid(points []Point) Point {
+
+	sum := vector{}
+
+	for _, p := range points {
+		if p != nil {
+			sum = sum.add(toVector(p))
+		}
+	}
+
+	if sum.norm() < epsilon {
+		return nil
+	}
+
+	return sum.toPoint()
+}
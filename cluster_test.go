@@ -0,0 +1,63 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCluster_Nil(t *testing.T) {
+
+	var c *cluster
+	var cl Cluster = c
+
+	assert.Nil(t, cl.Indices())
+	assert.Nil(t, cl.Centroid())
+	assert.Nil(t, cl.Boundary())
+
+	var r *clustering
+	var cr Clustering = r
+
+	assert.Nil(t, cr.Labels())
+	assert.Nil(t, cr.Clusters())
+	assert.Nil(t, cr.Noise())
+}
+
+func TestNewClustering(t *testing.T) {
+
+	points := []Point{NewPoint(1, 179), NewPoint(0, 0), NewPoint(-1, -179), NewPoint(10, 10)}
+	c := newClustering(points, []int{0, 1, 0, Noise}, 2)
+
+	assert.Equal(t, []int{0, 1, 0, Noise}, c.Labels())
+	assert.Equal(t, []int{3}, c.Noise())
+	assert.Len(t, c.Clusters(), 2)
+	assert.Equal(t, []int{0, 2}, c.Clusters()[0].Indices())
+
+	centroid := c.Clusters()[0].Centroid()
+	assert.InDelta(t, 0, centroid.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 180, centroid.Longitude(), DecimalPrecision)
+
+	b := c.Clusters()[0].Boundary()
+	assert.InDelta(t, -1, b.Lower().Latitude(), 0)
+	assert.InDelta(t, 179, b.Lower().Longitude(), 0)
+	assert.InDelta(t, 1, b.Upper().Latitude(), 0)
+	assert.InDelta(t, -179, b.Upper().Longitude(), 0)
+
+	assert.Nil(t, getCentroid([]Point{NewPoint(0, 0), NewPoint(0, 180)}))
+}
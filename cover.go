@@ -0,0 +1,194 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+// getCapRange returns the smallest latlng range containing the cap centered at the given geo-location point
+// with the given angular radius in radians.
+// The longitude range is not normalized, so it may go beyond -180 or +180 when the cap crosses the antimeridian,
+// and it is the full range from -180 to +180 when the cap contains a pole.
+func getCapRange(center Point, radius float64) (minLat, maxLat, minLng, maxLng float64) {
+
+	lat := center.Latitude()
+	lng := center.Longitude()
+	r := radius / radians
+
+	minLat = lat - r
+	maxLat = lat + r
+
+	if radius >= math.Pi || maxLat >= NorthPoleLat || minLat <= SouthPoleLat {
+		return math.Max(minLat, SouthPoleLat), math.Min(maxLat, NorthPoleLat), -HalfLongitude, HalfLongitude
+	}
+
+	// The meridians tangent to the cap are the ones bounding it, which is not where the cap crosses the
+	// center latitude parallel.
+	d := math.Asin(math.Sin(radius)/math.Cos(lat*radians)) / radians
+
+	return minLat, maxLat, lng - d, lng + d
+}
+
+// getHashCellSize returns the height and the width in degrees of the geohash cell of the given precision.
+func getHashCellSize(precision uint8) (float64, float64) {
+	latBits := precision / 2
+	lngBits := precision - latBits
+	return (NorthPoleLat - SouthPoleLat) / math.Pow(2, float64(latBits)), TotalLongitude / math.Pow(2, float64(lngBits))
+}
+
+// getCoveringHashes returns the geohashes with the given precision of all the cells intersecting the given
+// latlng range, where the longitude range starts at minLng and goes eastward till maxLng, even beyond +180.
+func getCoveringHashes(minLat, maxLat, minLng, maxLng float64, precision uint8) []Hash {
+
+	precision = uint8(math.Min(float64(precision), MaxHashBits))
+
+	if maxLng-minLng >= TotalLongitude {
+		minLng, maxLng = -HalfLongitude, HalfLongitude
+	}
+
+	// Stepping by half a cell makes sure no cell is skipped because of rounding the sampled latlng values.
+	height, width := getHashCellSize(precision)
+	height /= 2
+	width /= 2
+
+	seen := make(map[uint64]bool)
+	hashes := []Hash{}
+
+	for lat := minLat; ; lat += height {
+		lat = math.Min(lat, maxLat)
+
+		for lng := minLng; ; lng += width {
+			lng = math.Min(lng, maxLng)
+
+			h := GetHash(NewPoint(lat, lng), precision)
+
+			if !seen[h.Bits()] {
+				seen[h.Bits()] = true
+				hashes = append(hashes, h)
+			}
+
+			if lng == maxLng {
+				break
+			}
+		}
+
+		if lat == maxLat {
+			break
+		}
+	}
+
+	return hashes
+}
+
+// getRadiusPrecision returns the geohash precision needed for the given radius in kilometers as GetNeededPrecision
+// does, capped at MaxHashBits, which is also the precision used for a zero radius.
+func getRadiusPrecision(radiusInKM float64) uint8 {
+
+	// GetNeededPrecision never returns for a zero radius, and goes beyond MaxHashBits for tiny ones.
+	if !(radiusInKM > EarthRadiusInKM/math.Pow(2, MaxHashBits/2)) {
+		return MaxHashBits
+	}
+
+	return GetNeededPrecision(radiusInKM)
+}
+
+// maxCoveringHashes is the number of geohash cells above which a covering is done with a lower precision.
+const maxCoveringHashes = 64
+
+// getCoveringPrecision returns the highest precision not exceeding the given one that covers the given latlng
+// range with a reasonable number of geohash cells.
+func getCoveringPrecision(minLat, maxLat, minLng, maxLng float64, precision uint8) uint8 {
+
+	lngSpan := math.Min(maxLng-minLng, TotalLongitude)
+
+	for ; precision > 0; precision-- {
+		height, width := getHashCellSize(precision)
+		count := (math.Floor((maxLat-minLat)/height) + 2) * (math.Floor(lngSpan/width) + 2)
+
+		if count <= maxCoveringHashes {
+			break
+		}
+	}
+
+	return precision
+}
+
+// hashBuckets groups the indices of geo-location points by their geohash having a fixed precision,
+// it's used to find the points within a latlng range without scanning all of them.
+type hashBuckets struct {
+	precision uint8
+	keys      []uint64
+	buckets   map[uint64][]int
+}
+
+func newHashBuckets(points []Point, precision uint8) *hashBuckets {
+
+	b := &hashBuckets{precision: precision, buckets: make(map[uint64][]int)}
+
+	for i, p := range points {
+		if p != nil {
+			b.add(GetHash(p, precision).Bits(), i)
+		}
+	}
+
+	return b
+}
+
+func (b *hashBuckets) add(key uint64, i int) {
+
+	if _, ok := b.buckets[key]; !ok {
+		at := sort.Search(len(b.keys), func(j int) bool { return b.keys[j] >= key })
+		b.keys = append(b.keys, 0)
+		copy(b.keys[at+1:], b.keys[at:])
+		b.keys[at] = key
+	}
+
+	b.buckets[key] = append(b.buckets[key], i)
+}
+
+// query calls the given function with the indices of all the points in the geohash cells intersecting
+// the given latlng range, which may include points outside of that range.
+func (b *hashBuckets) query(minLat, maxLat, minLng, maxLng float64, fn func(i int)) {
+
+	precision := getCoveringPrecision(minLat, maxLat, minLng, maxLng, b.precision)
+
+	if precision == 0 {
+		for _, key := range b.keys {
+			for _, i := range b.buckets[key] {
+				fn(i)
+			}
+		}
+		return
+	}
+
+	// Cells of a lower precision are prefixes of the cells of the bucket precision,
+	// so each of them covers a contiguous range of the sorted keys.
+	span := uint64(1) << (64 - precision)
+
+	for _, h := range getCoveringHashes(minLat, maxLat, minLng, maxLng, precision) {
+		lower := h.Bits()
+		at := sort.Search(len(b.keys), func(j int) bool { return b.keys[j] >= lower })
+
+		for ; at < len(b.keys) && b.keys[at]-lower < span; at++ {
+			for _, i := range b.buckets[b.keys[at]] {
+				fn(i)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCapRange(t *testing.T) {
+
+	minLat, maxLat, minLng, maxLng := getCapRange(NewPoint(0, 0), kmToAngle(111.195))
+	assert.InDelta(t, -1, minLat, 0.001)
+	assert.InDelta(t, 1, maxLat, 0.001)
+	assert.InDelta(t, -1, minLng, 0.001)
+	assert.InDelta(t, 1, maxLng, 0.001)
+
+	minLat, maxLat, minLng, maxLng = getCapRange(NewPoint(60, 179.5), kmToAngle(111.195))
+	assert.InDelta(t, 59, minLat, 0.001)
+	assert.InDelta(t, 61, maxLat, 0.001)
+	assert.True(t, minLng < 179.5-2)
+	assert.True(t, maxLng > 181.5)
+
+	minLat, maxLat, minLng, maxLng = getCapRange(NewPoint(89.5, 10), kmToAngle(111.195))
+	assert.InDelta(t, 88.5, minLat, 0.001)
+	assert.InDelta(t, 90, maxLat, 0)
+	assert.InDelta(t, -180, minLng, 0)
+	assert.InDelta(t, 180, maxLng, 0)
+}
+
+func TestGetCoveringHashes(t *testing.T) {
+
+	hashes := getCoveringHashes(-90, 90, -180, 180, 5)
+	assert.Len(t, hashes, 32)
+
+	hashes = getCoveringHashes(0.1, 0.2, 179.9, 180.1, 10)
+	strs := []string{}
+	for _, h := range hashes {
+		strs = append(strs, h.String())
+	}
+	assert.ElementsMatch(t, []string{GetHash(NewPoint(0.15, 179.95), 10).String(), GetHash(NewPoint(0.15, -179.95), 10).String()}, strs)
+}
+
+func TestGetRadiusPrecision(t *testing.T) {
+	assert.Equal(t, GetNeededPrecision(1), getRadiusPrecision(1))
+	assert.Equal(t, GetNeededPrecision(0.001), getRadiusPrecision(0.001))
+	assert.Equal(t, uint8(MaxHashBits), getRadiusPrecision(0))
+	assert.Equal(t, uint8(MaxHashBits), getRadiusPrecision(1e-300))
+	assert.Equal(t, uint8(MaxHashBits), getRadiusPrecision(math.NaN()))
+	assert.True(t, getRadiusPrecision(1e-5) <= MaxHashBits)
+}
+
+func TestHashBuckets(t *testing.T) {
+
+	points := make([]Point, 10000)
+
+	for i := range points {
+		points[i] = NewPoint(rand.Float64()*180-90, rand.Float64()*360-180)
+	}
+
+	b := newHashBuckets(points, 30)
+
+	for i := 0; i < 100; i++ {
+		center := points[rand.Intn(len(points))]
+		radius := kmToAngle(rand.Float64() * 1000)
+		minLat, maxLat, minLng, maxLng := getCapRange(center, radius)
+
+		found := map[int]bool{}
+		b.query(minLat, maxLat, minLng, maxLng, func(j int) {
+			assert.False(t, found[j])
+			found[j] = true
+		})
+
+		for j, p := range points {
+			if toVector(center).angle(toVector(p)) <= radius {
+				assert.True(t, found[j])
+			}
+		}
+	}
+}
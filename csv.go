@@ -0,0 +1,302 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownColumn is returned when a column given by name is not in the header, or given by index is
+	// beyond the fields of a row.
+	ErrUnknownColumn = errors.New("unknown column")
+	// ErrInvalidCoordinates is returned when a row has a location that can't be parsed, or that is out of the
+	// latlng ranges.
+	ErrInvalidCoordinates = errors.New("invalid coordinates")
+)
+
+// LocationFormat is the way the location of a row is stored in the columns.
+type LocationFormat byte
+
+const (
+	// LatLngColumns is a location stored as separate latitude and longitude columns in degrees.
+	LatLngColumns LocationFormat = iota
+	// WKTColumn is a location stored as a WKT point in a single column, such as "POINT (-122.25 45.5)".
+	WKTColumn
+	// GeohashColumn is a location stored as a Base32 geohash string in a single column.
+	GeohashColumn
+)
+
+// Column identifies a column by its header name, or by its zero based index when the name is empty.
+type Column struct {
+	// Name is the header name of the column.
+	Name string
+	// Index is the zero based index of the column, used when the name is empty.
+	Index int
+}
+
+// CSVOptions carries the options of reading rows of geo-location points.
+type CSVOptions struct {
+	// Comma is the field delimiter, such as '\t' for TSV, when it's zero ',' is used instead.
+	Comma rune
+	// Header is whether the first row holds the column names, which is needed to give the columns by name.
+	Header bool
+	// Format is the way the location of each row is stored.
+	Format LocationFormat
+	// Latitude is the latitude column for LatLngColumns.
+	Latitude Column
+	// Longitude is the longitude column for LatLngColumns.
+	Longitude Column
+	// Location is the single location column for WKTColumn and GeohashColumn.
+	Location Column
+	// HashPrecision is the precision of the hash calculated by GetHash for each row, when it's zero no hash
+	// is calculated, except for GeohashColumn where the hash is the one in the row.
+	HashPrecision uint8
+}
+
+// CSVRecord is a row of a CSV or TSV stream with its location.
+type CSVRecord struct {
+	// Line is the line number where the row starts.
+	Line int
+	// Point is the location of the row.
+	Point Point
+	// Hash is the hash of the location of the row, if any.
+	Hash Hash
+	// Fields are the values of the other columns of the row, in order.
+	Fields []string
+}
+
+// CSVError is a row that couldn't be read, which doesn't stop reading the rows after it.
+type CSVError struct {
+	// Line is the line number where the row starts.
+	Line int
+	// Err is the reason of the failure.
+	Err error
+}
+
+func (e *CSVError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *CSVError) Unwrap() error {
+	return e.Err
+}
+
+// CSVReader reads geo-location points from the rows of a CSV or TSV stream, one row at a time.
+type CSVReader struct {
+	reader   *csv.Reader
+	options  CSVOptions
+	header   []string
+	indices  []int
+	location map[int]bool
+}
+
+// NewCSVReader creates a new reader of the rows of the given stream, reading the header first if there's one,
+// in which case ErrUnknownColumn is returned if any of the location columns given by name is not in it.
+func NewCSVReader(r io.Reader, options CSVOptions) (*CSVReader, error) {
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	if options.Comma != 0 {
+		reader.Comma = options.Comma
+	}
+
+	c := &CSVReader{reader: reader, options: options}
+
+	if options.Header {
+		header, err := reader.Read()
+
+		if err != nil {
+			return nil, err
+		}
+
+		c.header = header
+	}
+
+	columns := []Column{options.Latitude, options.Longitude}
+
+	if options.Format != LatLngColumns {
+		columns = []Column{options.Location}
+	}
+
+	c.location = make(map[int]bool)
+
+	for _, column := range columns {
+		index := column.Index
+
+		if column.Name != "" {
+			index = -1
+
+			for i, name := range c.header {
+				if name == column.Name {
+					index = i
+					break
+				}
+			}
+		}
+
+		if index < 0 {
+			return nil, ErrUnknownColumn
+		}
+
+		c.indices = append(c.indices, index)
+		c.location[index] = true
+	}
+
+	return c, nil
+}
+
+// Columns returns the header names of the fields of the records, which are all the columns but the location
+// ones, or nil if there's no header.
+func (c *CSVReader) Columns() []string {
+
+	if c.header == nil {
+		return nil
+	}
+
+	columns := []string{}
+
+	for i, name := range c.header {
+		if !c.location[i] {
+			columns = append(columns, name)
+		}
+	}
+
+	return columns
+}
+
+// Read returns the next row of the stream, or io.EOF once there are no more rows.
+// A row that can't be read is returned as a CSVError, after which reading can go on with the next rows, while
+// any other error means the stream is broken.
+func (c *CSVReader) Read() (*CSVRecord, error) {
+
+	row, err := c.reader.Read()
+
+	if err != nil {
+		var parseErr *csv.ParseError
+
+		if errors.As(err, &parseErr) {
+			return nil, &CSVError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+
+		return nil, err
+	}
+
+	line, _ := c.reader.FieldPos(0)
+	record := &CSVRecord{Line: line}
+
+	for _, index := range c.indices {
+		if index >= len(row) {
+			return nil, &CSVError{Line: line, Err: ErrUnknownColumn}
+		}
+	}
+
+	switch c.options.Format {
+	case LatLngColumns:
+		record.Point, err = parseLatLng(row[c.indices[0]], row[c.indices[1]])
+	case WKTColumn:
+		record.Point, err = parseWKTPoint(row[c.indices[0]])
+	case GeohashColumn:
+		value := strings.TrimSpace(row[c.indices[0]])
+		record.Point = ReverseHash(value)
+
+		if record.Point == nil {
+			err = ErrInvalidCoordinates
+		} else {
+			record.Hash = GetHashFromString(value)
+		}
+	}
+
+	if err != nil {
+		return nil, &CSVError{Line: line, Err: err}
+	}
+
+	if c.options.HashPrecision > 0 {
+		record.Hash = GetHash(record.Point, c.options.HashPrecision)
+	}
+
+	record.Fields = make([]string, 0, len(row))
+
+	for i, value := range row {
+		if !c.location[i] {
+			record.Fields = append(record.Fields, value)
+		}
+	}
+
+	return record, nil
+}
+
+// parseLatLng returns the geo-location point of the given latitude and longitude in degrees, or
+// ErrInvalidCoordinates if any of them is not a number within its range.
+func parseLatLng(latitude, longitude string) (Point, error) {
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latitude), 64)
+
+	if err != nil || math.IsNaN(lat) || math.Abs(lat) > NorthPoleLat {
+		return nil, ErrInvalidCoordinates
+	}
+
+	lng, err := strconv.ParseFloat(strings.TrimSpace(longitude), 64)
+
+	if err != nil || math.IsNaN(lng) || math.Abs(lng) > HalfLongitude {
+		return nil, ErrInvalidCoordinates
+	}
+
+	return NewPoint(lat, lng), nil
+}
+
+// parseWKTPoint returns the geo-location point of the given WKT point, such as "POINT (-122.25 45.5)", where the
+// longitude comes first and any altitude is ignored.
+func parseWKTPoint(wkt string) (Point, error) {
+
+	s := strings.TrimSpace(wkt)
+
+	if len(s) < 5 || !strings.EqualFold(s[:5], "POINT") {
+		return nil, ErrInvalidCoordinates
+	}
+
+	s = strings.TrimSpace(s[5:])
+
+	// Dimension markers, such as POINT Z (1 2 3).
+	if i := strings.Index(s, "("); i > 0 {
+		switch strings.ToUpper(strings.TrimSpace(s[:i])) {
+		case "Z", "M", "ZM":
+			s = s[i:]
+		default:
+			return nil, ErrInvalidCoordinates
+		}
+	}
+
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return nil, ErrInvalidCoordinates
+	}
+
+	values := strings.Fields(s[1 : len(s)-1])
+
+	if len(values) < 2 || len(values) > 4 {
+		return nil, ErrInvalidCoordinates
+	}
+
+	return parseLatLng(values[1], values[0])
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readCSV returns all the records and the row errors of the given reader.
+func readCSV(t *testing.T, r *CSVReader) ([]*CSVRecord, []*CSVError) {
+
+	records := []*CSVRecord{}
+	failures := []*CSVError{}
+
+	for {
+		record, err := r.Read()
+
+		if err == io.EOF {
+			return records, failures
+		}
+
+		var rowErr *CSVError
+
+		if errors.As(err, &rowErr) {
+			failures = append(failures, rowErr)
+			continue
+		}
+
+		assert.Nil(t, err)
+		records = append(records, record)
+	}
+}
+
+func TestCSVReader_LatLng(t *testing.T) {
+
+	data := "id,lat,lng,name\n" +
+		"1,45.5,-122.25,depot\n" +
+		"2,abc,-122.25,broken\n" +
+		"3,95,10,north of the pole\n" +
+		"4,45.6\n" +
+		"5,\"45.7\",-122.3,\"multi\nline\"\n" +
+		"6,45.8,-122.4,last\n"
+
+	r, err := NewCSVReader(strings.NewReader(data), CSVOptions{
+		Header:        true,
+		Latitude:      Column{Name: "lat"},
+		Longitude:     Column{Name: "lng"},
+		HashPrecision: 30,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id", "name"}, r.Columns())
+
+	records, failures := readCSV(t, r)
+	assert.Len(t, records, 3)
+	assert.Equal(t, 2, records[0].Line)
+	assert.Equal(t, NewPoint(45.5, -122.25), records[0].Point)
+	assert.Equal(t, GetHash(NewPoint(45.5, -122.25), 30), records[0].Hash)
+	assert.Equal(t, []string{"1", "depot"}, records[0].Fields)
+	assert.Equal(t, 6, records[1].Line)
+	assert.Equal(t, []string{"5", "multi\nline"}, records[1].Fields)
+	assert.Equal(t, 8, records[2].Line)
+
+	assert.Len(t, failures, 3)
+	assert.Equal(t, 3, failures[0].Line)
+	assert.Equal(t, ErrInvalidCoordinates, failures[0].Err)
+	assert.Equal(t, 4, failures[1].Line)
+	assert.Equal(t, 5, failures[2].Line)
+	assert.Equal(t, ErrUnknownColumn, failures[2].Err)
+	assert.Equal(t, "line 5: unknown column", failures[2].Error())
+	assert.True(t, errors.Is(failures[2], ErrUnknownColumn))
+
+	_, err = NewCSVReader(strings.NewReader(data), CSVOptions{Header: true, Latitude: Column{Name: "latitude"}})
+	assert.Equal(t, ErrUnknownColumn, err)
+
+	_, err = NewCSVReader(strings.NewReader(""), CSVOptions{Header: true})
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCSVReader_TSV(t *testing.T) {
+
+	data := "-122.25\t45.5\ta\n" +
+		"-122.3\t\"45.6\tb\n" +
+		"-122.35\t45.7\tc\n"
+
+	r, err := NewCSVReader(strings.NewReader(data), CSVOptions{
+		Comma:     '\t',
+		Latitude:  Column{Index: 1},
+		Longitude: Column{Index: 0},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, r.Columns())
+
+	records, failures := readCSV(t, r)
+	assert.Len(t, records, 1)
+	assert.Equal(t, []string{"a"}, records[0].Fields)
+	assert.Nil(t, records[0].Hash)
+
+	// The unbalanced quote swallows the rest of the stream.
+	assert.Len(t, failures, 1)
+	assert.Equal(t, 2, failures[0].Line)
+}
+
+func TestCSVReader_WKT(t *testing.T) {
+
+	data := "name;geometry\n" +
+		"a;POINT (-122.25 45.5)\n" +
+		"b;point z(10 20 30)\n" +
+		"c;POINT EMPTY\n" +
+		"d;LINESTRING (1 2, 3 4)\n" +
+		"e;POINT (200 10)\n"
+
+	r, err := NewCSVReader(strings.NewReader(data), CSVOptions{
+		Comma:    ';',
+		Header:   true,
+		Format:   WKTColumn,
+		Location: Column{Name: "geometry"},
+	})
+	assert.Nil(t, err)
+
+	records, failures := readCSV(t, r)
+	assert.Len(t, records, 2)
+	assert.Equal(t, NewPoint(45.5, -122.25), records[0].Point)
+	assert.Equal(t, NewPoint(20, 10), records[1].Point)
+	assert.Equal(t, []string{"b"}, records[1].Fields)
+	assert.Len(t, failures, 3)
+}
+
+func TestCSVReader_Geohash(t *testing.T) {
+
+	data := "ub188qkx,a\n" +
+		"ub188qka,b\n" +
+		"gbsuv7,c\n"
+
+	r, err := NewCSVReader(strings.NewReader(data), CSVOptions{Format: GeohashColumn})
+	assert.Nil(t, err)
+
+	records, failures := readCSV(t, r)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "ub188qkx", records[0].Hash.String())
+	assert.Equal(t, ReverseHash("ub188qkx"), records[0].Point)
+	assert.Equal(t, []string{"a"}, records[0].Fields)
+	assert.Equal(t, "gbsuv7", records[1].Hash.String())
+	assert.Len(t, failures, 1)
+	assert.Equal(t, 2, failures[0].Line)
+}
+
+func TestParseWKTPoint(t *testing.T) {
+
+	p, err := parseWKTPoint(" POINT(1.5 -2.5) ")
+	assert.Nil(t, err)
+	assert.Equal(t, NewPoint(-2.5, 1.5), p)
+
+	p, err = parseWKTPoint("POINT ZM (1 2 3 4)")
+	assert.Nil(t, err)
+	assert.Equal(t, NewPoint(2, 1), p)
+
+	for _, wkt := range []string{"", "POINT", "POINT ()", "POINT (1)", "POINT (1 2", "POINT Q (1 2)", "POINT (a b)"} {
+		_, err = parseWKTPoint(wkt)
+		assert.Equal(t, ErrInvalidCoordinates, err, wkt)
+	}
+}
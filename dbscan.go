@@ -0,0 +1,109 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+// GetDBSCANClusters groups the given geo-location points using the DBSCAN density based clustering algorithm,
+// where the distance between the points is their great circle distance in kilometers.
+// A point having at least minPoints points (itself included) within epsInKM is a core point, clusters are formed
+// of the core points reachable from each other and the points within their reach, while the rest is noise.
+// Neighbours are looked up by bucketing the points into geohash cells sized after epsInKM,
+// so only the nearby cells are scanned for each point.
+// A zero epsInKM only groups duplicate points, while a negative or NaN one labels all the points as noise.
+// Nil points are labeled as noise.
+func GetDBSCANClusters(points []Point, epsInKM float64, minPoints int) Clustering {
+
+	const unvisited = -2
+
+	labels := make([]int, len(points))
+
+	if !(epsInKM >= 0) {
+		for i := range labels {
+			labels[i] = Noise
+		}
+		return newClustering(points, labels, 0)
+	}
+
+	vectors := make([]vector, len(points))
+	buckets := newHashBuckets(points, getRadiusPrecision(epsInKM))
+
+	for i, p := range points {
+		labels[i] = unvisited
+
+		if p == nil {
+			labels[i] = Noise
+			continue
+		}
+
+		vectors[i] = toVector(p)
+	}
+
+	eps := kmToAngle(epsInKM)
+
+	neighboursOf := func(i int) []int {
+		minLat, maxLat, minLng, maxLng := getCapRange(points[i], eps)
+		result := []int{}
+
+		buckets.query(minLat, maxLat, minLng, maxLng, func(j int) {
+			if vectors[i].angle(vectors[j]) <= eps {
+				result = append(result, j)
+			}
+		})
+
+		return result
+	}
+
+	count := 0
+
+	for i := range points {
+		if labels[i] != unvisited {
+			continue
+		}
+
+		neighbours := neighboursOf(i)
+
+		if len(neighbours) < minPoints {
+			labels[i] = Noise
+			continue
+		}
+
+		labels[i] = count
+
+		for len(neighbours) > 0 {
+			j := neighbours[len(neighbours)-1]
+			neighbours = neighbours[:len(neighbours)-1]
+
+			if labels[j] == Noise {
+				// A border point, it's reachable but it doesn't expand the cluster.
+				labels[j] = count
+			}
+
+			if labels[j] != unvisited {
+				continue
+			}
+
+			labels[j] = count
+
+			if next := neighboursOf(j); len(next) >= minPoints {
+				neighbours = append(neighbours, next...)
+			}
+		}
+
+		count++
+	}
+
+	return newClustering(points, labels, count)
+}
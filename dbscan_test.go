@@ -0,0 +1,96 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDBSCANClusters(t *testing.T) {
+
+	points := []Point{}
+
+	// Two dense groups, one of them around the antimeridian, and a few scattered points.
+	for i := 0; i < 50; i++ {
+		points = append(points, NewPoint(30+rand.Float64()*0.01, 31+rand.Float64()*0.01))
+		points = append(points, NewPoint(-15+rand.Float64()*0.01, 179.995+rand.Float64()*0.01))
+	}
+
+	points = append(points, NewPoint(10, 10), NewPoint(-10, -10), nil)
+
+	c := GetDBSCANClusters(points, 1, 5)
+
+	assert.Len(t, c.Clusters(), 2)
+	assert.Equal(t, []int{100, 101, 102}, c.Noise())
+	assert.Len(t, c.Clusters()[0].Indices(), 50)
+	assert.Len(t, c.Clusters()[1].Indices(), 50)
+
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, i%2, c.Labels()[i])
+	}
+
+	b := c.Clusters()[1].Boundary()
+	assert.True(t, b.Lower().Longitude() > b.Upper().Longitude())
+	assert.InDelta(t, -14.995, c.Clusters()[1].Centroid().Latitude(), 0.005)
+}
+
+func TestGetDBSCANClusters_Eps(t *testing.T) {
+
+	points := []Point{NewPoint(1, 1), NewPoint(1, 1), NewPoint(1, 1.00001), nil}
+
+	// Only the duplicates are within a zero distance of each other.
+	c := GetDBSCANClusters(points, 0, 2)
+	assert.Equal(t, []int{0, 0, Noise, Noise}, c.Labels())
+
+	for _, eps := range []float64{-1, math.NaN()} {
+		c = GetDBSCANClusters(points, eps, 1)
+		assert.Empty(t, c.Clusters())
+		assert.Equal(t, []int{Noise, Noise, Noise, Noise}, c.Labels())
+	}
+}
+
+func TestGetDBSCANClusters_BruteForce(t *testing.T) {
+
+	points := make([]Point, 500)
+
+	for i := range points {
+		points[i] = NewPoint(85+rand.Float64()*5, rand.Float64()*360-180)
+	}
+
+	eps := 100.0
+	c := GetDBSCANClusters(points, eps, 4)
+
+	// Every core point has to share the cluster of all its neighbours.
+	for i, p := range points {
+		neighbours := []int{}
+		for j, q := range points {
+			if angleToKM(toVector(p).angle(toVector(q))) <= eps {
+				neighbours = append(neighbours, j)
+			}
+		}
+
+		if len(neighbours) >= 4 {
+			for _, j := range neighbours {
+				assert.Equal(t, c.Labels()[i], c.Labels()[j])
+			}
+		}
+	}
+}
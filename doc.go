@@ -28,6 +28,43 @@ The package has a few features that might be handy, including:
 - Calculating the needed geo-hash precision given the radius in kilometers.
 - Calculating the neighbour geo-hashes of the given geo-hash.
 - Creating normalized boundary boxes given two geo-points.
+- Calculating the smallest circle enclosing a set of geo-points.
+- Calculating the spherical convex hull of a set of geo-points.
+- Clustering geo-points by density using DBSCAN.
+- Clustering geo-points around centers using spherical k-means or k-medoids.
+- Simplifying lines of geo-points using Douglas-Peucker or Visvalingam-Whyatt.
+- Measuring, interpolating and resampling lines of geo-points.
+- Testing whether polygons with holes contain geo-points.
+- Measuring the area and perimeter of polygons on the sphere or the WGS84 ellipsoid.
+- Validating and repairing polygons.
+- Union, intersection, difference and symmetric difference of polygons and boundaries.
+- Buffering geo-points, lines and polygons by a distance.
+- Testing whether circles contain geo-points or intersect boundaries.
+- Intersecting great circle arcs and paths.
+- Snapping geo-points to the nearest location on lines or rings.
+- Comparing lines of geo-points using Fréchet, Hausdorff or dynamic time warping distances.
+- Measuring speeds, headings and stay points of timestamped tracks.
+- Reading and writing GPX 1.1 documents.
+- Reading and writing KML documents and KMZ archives.
+- Streaming geo-points out of CSV or TSV rows.
+- Parsing NMEA 0183 sentences of GPS receivers.
+- Geofencing moving objects with enter, exit and dwell events.
+- Indexing values by boundaries in an R-tree for boundary and nearest neighbour searches.
+- Indexing geo-points in a geohash prefix trie for cell, boundary and radius searches.
+- Finding the k nearest geo-points to a query point.
+- Indexing geo-points under any distance function in a vantage point tree.
+
+Distances
+
+The package measures distances in two ways. GetDistance uses the haversine formula with DegToRad, which
+approximates pi by 22/7, and so do the APIs documented as measuring with it, such as the track distances and
+speeds, the k-means and k-medoids costs, the default metric of the line comparisons, and the R-tree, geohash
+index and nearest neighbour searches.
+
+The geometry of lines, polygons, circles and buffers, along with the simplification tolerances, the snapping,
+the enclosing circle and DBSCAN, measures great circle distances on a sphere of EarthRadiusInKM radius with the
+exact value of pi instead. Away from the poles GetDistance measures about 0.04% longer, so near the edge of a
+circle, a point the circle contains can be beyond its radius by GetDistance.
 
 Usage
 
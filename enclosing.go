@@ -0,0 +1,154 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+var (
+	// ErrEmptyPointSet is returned when a calculation needs at least one geo-location point but none was given.
+	ErrEmptyPointSet = errors.New("empty point set")
+	// ErrNotInHemisphere is returned when the given geo-location points can't all fit in a single open hemisphere,
+	// such as points spread around the Equator on all sides of earth.
+	ErrNotInHemisphere = errors.New("points do not fit in a single hemisphere")
+)
+
+// enclosingCap is a spherical cap represented by the unit vector of its center and its angular radius in radians.
+type enclosingCap struct {
+	center vector
+	radius float64
+}
+
+func (c enclosingCap) contains(v vector) bool {
+	return c.center.angle(v) <= c.radius+epsilon
+}
+
+func capFromTwo(a, b vector) enclosingCap {
+	center := a.add(b).normalize()
+	return enclosingCap{center: center, radius: a.angle(b) / 2}
+}
+
+func capFromThree(a, b, c vector) enclosingCap {
+	center := b.sub(a).cross(c.sub(a)).normalize()
+
+	if center.norm() == 0 {
+		// Degenerate triangle, duplicate points are covered by the cap of the other two.
+		return largestCapFromTwo(a, b, c)
+	}
+
+	if center.dot(a) < 0 {
+		center = center.scale(-1)
+	}
+
+	return enclosingCap{center: center, radius: center.angle(a)}
+}
+
+func largestCapFromTwo(a, b, c vector) enclosingCap {
+	result := capFromTwo(a, b)
+
+	for _, cp := range []enclosingCap{capFromTwo(a, c), capFromTwo(b, c)} {
+		if cp.radius > result.radius {
+			result = cp
+		}
+	}
+
+	return result
+}
+
+// GetEnclosingCircle returns the center and the radius in kilometers of the smallest circle (spherical cap) on the
+// surface of earth that contains all the given geo-location points.
+// The calculation is done on the sphere, so it works as well for points around the antimeridian or around a pole.
+// The points must fit in a single hemisphere, otherwise ErrNotInHemisphere is returned.
+// The result can be fed to GetNeededPrecision to find the geohash precision covering all the points.
+func GetEnclosingCircle(points []Point) (Point, float64, error) {
+
+	vectors := make([]vector, 0, len(points))
+
+	for _, p := range points {
+		if p != nil {
+			vectors = append(vectors, toVector(p))
+		}
+	}
+
+	c, err := getEnclosingCap(vectors)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return c.center.toPoint(), angleToKM(c.radius), nil
+}
+
+// getEnclosingCap returns the smallest cap containing all the given unit vectors, the order of the given
+// vectors is shuffled in place.
+func getEnclosingCap(vectors []vector) (enclosingCap, error) {
+
+	if len(vectors) == 0 {
+		return enclosingCap{}, ErrEmptyPointSet
+	}
+
+	// Shuffling with a fixed seed keeps the expected linear running time while having reproducible results.
+	rnd := rand.New(rand.NewSource(int64(len(vectors))))
+	rnd.Shuffle(len(vectors), func(i, j int) {
+		vectors[i], vectors[j] = vectors[j], vectors[i]
+	})
+
+	c := enclosingCap{center: vectors[0]}
+
+	for i := 1; i < len(vectors); i++ {
+		if c.contains(vectors[i]) {
+			continue
+		}
+
+		c = enclosingCap{center: vectors[i]}
+
+		for j := 0; j < i; j++ {
+			if c.contains(vectors[j]) {
+				continue
+			}
+
+			if vectors[i].add(vectors[j]).norm() < epsilon {
+				return enclosingCap{}, ErrNotInHemisphere
+			}
+
+			c = capFromTwo(vectors[i], vectors[j])
+
+			for k := 0; k < j; k++ {
+				if !c.contains(vectors[k]) {
+					c = capFromThree(vectors[i], vectors[j], vectors[k])
+				}
+			}
+		}
+	}
+
+	// Points that don't fit in a hemisphere break the convexity the algorithm relies on,
+	// which shows up as a cap that doesn't contain all the points.
+	if c.radius >= math.Pi/2-epsilon {
+		return enclosingCap{}, ErrNotInHemisphere
+	}
+
+	for _, v := range vectors {
+		if !c.contains(v) {
+			return enclosingCap{}, ErrNotInHemisphere
+		}
+	}
+
+	return c, nil
+}
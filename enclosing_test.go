@@ -0,0 +1,113 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertEnclosing(t *testing.T, points []Point, center Point, radius float64) {
+	for _, p := range points {
+		assert.True(t, angleToKM(toVector(center).angle(toVector(p))) <= radius+0.001)
+	}
+}
+
+func TestGetEnclosingCircle(t *testing.T) {
+
+	_, _, err := GetEnclosingCircle(nil)
+	assert.Equal(t, ErrEmptyPointSet, err)
+
+	center, radius, err := GetEnclosingCircle([]Point{NewPoint(10, 20)})
+	assert.NoError(t, err)
+	assert.InDelta(t, 10, center.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 20, center.Longitude(), DecimalPrecision)
+	assert.InDelta(t, 0, radius, DecimalPrecision)
+
+	center, radius, err = GetEnclosingCircle([]Point{NewPoint(0, 0), NewPoint(0, 2), NewPoint(0, 1)})
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, center.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 1, center.Longitude(), DecimalPrecision)
+	assert.InDelta(t, 111.195, radius, 0.01)
+}
+
+func TestGetEnclosingCircle_Antimeridian(t *testing.T) {
+
+	points := []Point{NewPoint(1, 179), NewPoint(-1, -179), NewPoint(1, -179), NewPoint(-1, 179), NewPoint(0, 180)}
+
+	center, radius, err := GetEnclosingCircle(points)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, center.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 180, center.Longitude(), DecimalPrecision)
+	assert.InDelta(t, 157.25, radius, 0.1)
+	assertEnclosing(t, points, center, radius)
+}
+
+func TestGetEnclosingCircle_Pole(t *testing.T) {
+
+	points := []Point{}
+
+	for lng := -180.0; lng < 180; lng += 15 {
+		points = append(points, NewPoint(80, lng))
+	}
+
+	center, radius, err := GetEnclosingCircle(points)
+	assert.NoError(t, err)
+	assert.InDelta(t, 90, center.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 1111.95, radius, 0.1)
+	assertEnclosing(t, points, center, radius)
+}
+
+func TestGetEnclosingCircle_NotInHemisphere(t *testing.T) {
+
+	_, _, err := GetEnclosingCircle([]Point{NewPoint(0, 0), NewPoint(0, 180)})
+	assert.Equal(t, ErrNotInHemisphere, err)
+
+	_, _, err = GetEnclosingCircle([]Point{NewPoint(0, 0), NewPoint(0, 120), NewPoint(0, -120)})
+	assert.Equal(t, ErrNotInHemisphere, err)
+
+	_, _, err = GetEnclosingCircle([]Point{NewPoint(0, 0), NewPoint(0, 120), NewPoint(0, -120), NewPoint(90, 0), NewPoint(-90, 0)})
+	assert.Equal(t, ErrNotInHemisphere, err)
+}
+
+func TestGetEnclosingCircle_Random(t *testing.T) {
+
+	for i := 0; i < 100; i++ {
+		lat := rand.Float64()*180 - 90
+		lng := rand.Float64()*360 - 180
+		points := make([]Point, 50)
+
+		for j := range points {
+			points[j] = NewPoint(lat+rand.Float64()*10-5, lng+rand.Float64()*10-5)
+		}
+
+		center, radius, err := GetEnclosingCircle(points)
+		assert.NoError(t, err)
+		assertEnclosing(t, points, center, radius)
+
+		// The smallest circle must touch at least two of the points.
+		touching := 0
+		for _, p := range points {
+			if angleToKM(toVector(center).angle(toVector(p))) >= radius-0.001 {
+				touching++
+			}
+		}
+		assert.True(t, touching >= 2)
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+)
+
+const (
+	// WGS84SemiMajorAxisInKM is the equatorial radius of the WGS84 reference ellipsoid measured in kilometers.
+	WGS84SemiMajorAxisInKM float64 = 6378.137
+	// WGS84Flattening is the flattening of the WGS84 reference ellipsoid.
+	WGS84Flattening float64 = 1 / 298.257223563
+	// vincentyMaxIterations is the maximum number of iterations done by Vincenty's formulae before giving up.
+	vincentyMaxIterations = 200
+)
+
+// wgs84SemiMinorAxisInKM is the polar radius of the WGS84 reference ellipsoid measured in kilometers.
+var wgs84SemiMinorAxisInKM = WGS84SemiMajorAxisInKM * (1 - WGS84Flattening)
+
+// vincentyCoefficients returns the A and B coefficients of Vincenty's formulae given the squared cosine of the
+// geodesic azimuth at the Equator.
+func vincentyCoefficients(cos2Alpha float64) (float64, float64) {
+	a, b := WGS84SemiMajorAxisInKM, wgs84SemiMinorAxisInKM
+	u2 := cos2Alpha * (a*a - b*b) / (b * b)
+	return 1 + u2/16384*(4096+u2*(-768+u2*(320-175*u2))), u2 / 1024 * (256 + u2*(-128+u2*(74-47*u2)))
+}
+
+// vincentyDeltaSigma returns the difference between the angular distance on the auxiliary sphere and the one
+// of the ellipsoid, as used by Vincenty's formulae.
+func vincentyDeltaSigma(b, sinSigma, cosSigma, cos2SigmaM float64) float64 {
+	return b * sinSigma * (cos2SigmaM + b/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		b/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+}
+
+// vincentyInverse returns the length in kilometers of the geodesic between the two given geo-location points on
+// the WGS84 ellipsoid, along with its initial azimuth in radians clockwise from the north, using Vincenty's
+// inverse formula, it reports false if the formula didn't converge, which happens with nearly antipodal points.
+func vincentyInverse(p1, p2 Point) (float64, float64, bool) {
+
+	f := WGS84Flattening
+	l := math.Remainder(p2.Longitude()-p1.Longitude(), TotalLongitude) * radians
+	u1 := math.Atan((1 - f) * math.Tan(p1.Latitude()*radians))
+	u2 := math.Atan((1 - f) * math.Tan(p2.Latitude()*radians))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+
+	lambda := l
+	var sinLambda, cosLambda, sinSigma, cosSigma, sigma, cos2Alpha, cos2SigmaM float64
+
+	for i := 0; ; i++ {
+		if i == vincentyMaxIterations {
+			return 0, 0, false
+		}
+
+		sinLambda, cosLambda = math.Sincos(lambda)
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+
+		if sinSigma == 0 {
+			// Coincident points.
+			return 0, 0, true
+		}
+
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha = 1 - sinAlpha*sinAlpha
+		cos2SigmaM = 0
+
+		// Both points on the Equator leave cos2SigmaM at zero.
+		if cos2Alpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+
+		c := f / 16 * cos2Alpha * (4 + f*(4-3*cos2Alpha))
+		prev := lambda
+		lambda = l + (1-c)*f*sinAlpha*(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+
+		if math.Abs(lambda) > math.Pi {
+			return 0, 0, false
+		}
+
+		if math.Abs(lambda-prev) < 1e-12 {
+			break
+		}
+	}
+
+	a, b := vincentyCoefficients(cos2Alpha)
+	s := wgs84SemiMinorAxisInKM * a * (sigma - vincentyDeltaSigma(b, sinSigma, cosSigma, cos2SigmaM))
+	azimuth := math.Atan2(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+
+	return s, azimuth, true
+}
+
+// vincentyDirect returns the geo-location point at the given distance in kilometers along the geodesic starting
+// at the given point with the given initial azimuth in radians clockwise from the north on the WGS84 ellipsoid,
+// using Vincenty's direct formula.
+func vincentyDirect(p Point, azimuth float64, distanceInKM float64) Point {
+
+	f := WGS84Flattening
+	sinAlpha1, cosAlpha1 := math.Sincos(azimuth)
+	u1 := math.Atan((1 - f) * math.Tan(p.Latitude()*radians))
+	sinU1, cosU1 := math.Sincos(u1)
+	sigma1 := math.Atan2(math.Tan(u1), cosAlpha1)
+	sinAlpha := cosU1 * sinAlpha1
+	cos2Alpha := 1 - sinAlpha*sinAlpha
+	a, b := vincentyCoefficients(cos2Alpha)
+
+	first := distanceInKM / (wgs84SemiMinorAxisInKM * a)
+	sigma := first
+	var sinSigma, cosSigma, cos2SigmaM float64
+
+	for i := 0; i < vincentyMaxIterations; i++ {
+		cos2SigmaM = math.Cos(2*sigma1 + sigma)
+		sinSigma, cosSigma = math.Sincos(sigma)
+		prev := sigma
+		sigma = first + vincentyDeltaSigma(b, sinSigma, cosSigma, cos2SigmaM)
+
+		if math.Abs(sigma-prev) < 1e-12 {
+			break
+		}
+	}
+
+	sinSigma, cosSigma = math.Sincos(sigma)
+	cos2SigmaM = math.Cos(2*sigma1 + sigma)
+	tmp := sinU1*sinSigma - cosU1*cosSigma*cosAlpha1
+	lat := math.Atan2(sinU1*cosSigma+cosU1*sinSigma*cosAlpha1, (1-f)*math.Hypot(sinAlpha, tmp))
+	lambda := math.Atan2(sinSigma*sinAlpha1, cosU1*cosSigma-sinU1*sinSigma*cosAlpha1)
+	c := f / 16 * cos2Alpha * (4 + f*(4-3*cos2Alpha))
+	l := lambda - (1-c)*f*sinAlpha*(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+
+	return NewPoint(lat/radians, p.Longitude()+l/radians)
+}
+
+// GetEllipsoidDistance returns the length in kilometers of the geodesic between two given geo-location points on
+// the WGS84 ellipsoid using Vincenty's formulae, which is accurate to less than a millimeter.
+// For nearly antipodal points, where the formulae don't converge, the great circle distance on a sphere
+// having the mean radius of the ellipsoid is returned instead.
+func GetEllipsoidDistance(p1 Point, p2 Point) float64 {
+
+	if s, _, ok := vincentyInverse(p1, p2); ok {
+		return s
+	}
+
+	meanRadius := (2*WGS84SemiMajorAxisInKM + wgs84SemiMinorAxisInKM) / 3
+
+	return toVector(p1).angle(toVector(p2)) * meanRadius
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEllipsoidDistance(t *testing.T) {
+
+	// Flinders Peak to Buninyong, the example given by Vincenty.
+	flinders := NewPoint(-37.95103342, 144.42486789)
+	buninyong := NewPoint(-37.65282114, 143.92649554)
+
+	assert.InDelta(t, 54.972271, GetEllipsoidDistance(flinders, buninyong), 0.000001)
+	assert.InDelta(t, 54.972271, GetEllipsoidDistance(buninyong, flinders), 0.000001)
+	assert.InDelta(t, 0, GetEllipsoidDistance(flinders, flinders), 0)
+
+	assert.InDelta(t, 111.319491, GetEllipsoidDistance(NewPoint(0, 0), NewPoint(0, 1)), 0.000001)
+	assert.InDelta(t, 110.574389, GetEllipsoidDistance(NewPoint(0, 0), NewPoint(1, 0)), 0.000001)
+	assert.InDelta(t, 111.319491, GetEllipsoidDistance(NewPoint(0, 179.5), NewPoint(0, -179.5)), 0.000001)
+
+	// Nearly antipodal points, where Vincenty's formulae don't converge.
+	assert.InDelta(t, 20003.9, GetEllipsoidDistance(NewPoint(0, 0), NewPoint(0.5, 179.7)), 100)
+}
+
+func TestVincentyDirect(t *testing.T) {
+
+	flinders := NewPoint(-37.95103342, 144.42486789)
+	s, azimuth, ok := vincentyInverse(flinders, NewPoint(-37.65282114, 143.92649554))
+	assert.True(t, ok)
+	assert.InDelta(t, 306.86816, azimuth/radians+360, 0.00001)
+
+	p := vincentyDirect(flinders, azimuth, s)
+	assert.InDelta(t, -37.65282114, p.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 143.92649554, p.Longitude(), DecimalPrecision)
+
+	p = vincentyDirect(NewPoint(0, 179.5), 90*radians, 111.319491)
+	assert.InDelta(t, 0, p.Latitude(), DecimalPrecision)
+	assert.InDelta(t, -179.5, p.Longitude(), DecimalPrecision)
+}
@@ -0,0 +1,427 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrInvalidFence is returned when a fence is added with an empty ID or without an area.
+var ErrInvalidFence = errors.New("invalid fence")
+
+// ErrDuplicateFence is returned when a fence is added with the ID of a fence already in the registry.
+var ErrDuplicateFence = errors.New("duplicate fence")
+
+// GeofenceEventType is the type of a geofence event.
+type GeofenceEventType int
+
+const (
+	// GeofenceEnter is the type of the event emitted when an object gets inside a fence.
+	GeofenceEnter GeofenceEventType = iota
+	// GeofenceExit is the type of the event emitted when an object gets outside a fence it was inside.
+	GeofenceExit
+	// GeofenceDwell is the type of the event emitted once an object has been inside a fence for the dwell time.
+	GeofenceDwell
+)
+
+func (t GeofenceEventType) String() string {
+	switch t {
+	case GeofenceEnter:
+		return "enter"
+	case GeofenceExit:
+		return "exit"
+	case GeofenceDwell:
+		return "dwell"
+	}
+	return fmt.Sprintf("GeofenceEventType(%d)", int(t))
+}
+
+// GeofenceEvent is an event emitted by a geofence registry for an object update.
+type GeofenceEvent struct {
+	// Type is the type of the event.
+	Type GeofenceEventType
+	// FenceID is the ID of the fence the event is about.
+	FenceID string
+	// ObjectID is the ID of the object the event is about.
+	ObjectID string
+	// Point is the location of the object update emitting the event.
+	Point Point
+	// Time is the time of the object update emitting the event.
+	Time time.Time
+}
+
+func (e GeofenceEvent) String() string {
+	return fmt.Sprintf("%v %v %v at %v", e.ObjectID, e.Type, e.FenceID, e.Point)
+}
+
+// GeofenceOptions carries the options of a geofence registry.
+type GeofenceOptions struct {
+	// Hysteresis is the distance in kilometers an object has to be outside a fence it was inside before it's
+	// considered out of it, so a location jittering around the fence edge doesn't keep entering and exiting it,
+	// when it's zero an object exits a fence as soon as it's located outside of it.
+	Hysteresis float64
+	// DwellTime is the duration an object has to stay inside a fence before a dwell event is emitted,
+	// when it's zero no dwell events are emitted.
+	DwellTime time.Duration
+}
+
+// GeofenceRegistry keeps a set of fences identified by their IDs, along with the fences each object is inside,
+// and emits the events of the objects getting inside, outside or dwelling in the fences as their locations
+// are updated.
+// The fences are indexed by the geohash cells covering them, so an update only checks the fences around
+// the object and the ones it was inside.
+// A registry is safe for concurrent use.
+type GeofenceRegistry interface {
+	// AddBoundary adds a fence covering the given boundary.
+	AddBoundary(id string, b Boundary) error
+	// AddPolygon adds a fence covering the given polygon.
+	AddPolygon(id string, p Polygon) error
+	// AddMultiPolygon adds a fence covering all the polygons of the given collection.
+	AddMultiPolygon(id string, m MultiPolygon) error
+	// Remove removes the fence with the given ID, reporting whether it was found, the objects inside it
+	// are dropped out of it without emitting exit events.
+	Remove(id string) bool
+	// Fences returns the sorted IDs of the fences.
+	Fences() []string
+	// Update sets the location of the given object at the given time, returning the events it causes
+	// sorted by the fence ID, where the exit events come before the enter and dwell events.
+	Update(objectID string, p Point, t time.Time) []GeofenceEvent
+	// Inside returns the sorted IDs of the fences the given object is inside.
+	Inside(objectID string) []string
+	// Forget drops the given object without emitting any events.
+	Forget(objectID string)
+}
+
+// fence is a registered area along with the rings making up its edges.
+type fence struct {
+	id       string
+	boundary Boundary
+	contains func(p Point) bool
+	edges    [][]Point
+	// precision and cells are the geohash cells indexing the fence.
+	precision uint8
+	cells     []uint64
+}
+
+// edgeDistance returns the distance in kilometers from the given point to the nearest edge of the fence.
+func (f *fence) edgeDistance(p Point) float64 {
+
+	distance := math.Inf(1)
+
+	for _, ring := range f.edges {
+		if s := SnapToLine(p, ring); s != nil {
+			distance = math.Min(distance, s.Distance())
+		}
+	}
+
+	return distance
+}
+
+// stay is an object being inside a fence since the given time.
+type stay struct {
+	since time.Time
+	dwell bool
+}
+
+type geofenceRegistry struct {
+	mutex   sync.RWMutex
+	options GeofenceOptions
+	fences  map[string]*fence
+	// cells maps the geohash cells of each precision to the fences they cover.
+	cells   map[uint8]map[uint64]map[string]*fence
+	objects map[string]map[string]*stay
+}
+
+func (g *geofenceRegistry) add(f *fence) error {
+
+	if f.id == "" || f.boundary == nil {
+		return ErrInvalidFence
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if _, ok := g.fences[f.id]; ok {
+		return ErrDuplicateFence
+	}
+
+	minLat, maxLat := f.boundary.Lower().Latitude(), f.boundary.Upper().Latitude()
+	minLng, maxLng := getBoundaryLngRange(f.boundary)
+
+	f.precision = getCoveringPrecision(minLat, maxLat, minLng, maxLng, MaxHashBits)
+
+	if g.cells[f.precision] == nil {
+		g.cells[f.precision] = make(map[uint64]map[string]*fence)
+	}
+
+	cells := g.cells[f.precision]
+
+	for _, h := range getCoveringHashes(minLat, maxLat, minLng, maxLng, f.precision) {
+		key := h.Bits()
+		f.cells = append(f.cells, key)
+
+		if cells[key] == nil {
+			cells[key] = make(map[string]*fence)
+		}
+
+		cells[key][f.id] = f
+	}
+
+	g.fences[f.id] = f
+
+	return nil
+}
+
+func (g *geofenceRegistry) AddBoundary(id string, b Boundary) error {
+
+	if b == nil {
+		return ErrInvalidFence
+	}
+
+	return g.add(&fence{
+		id:       id,
+		boundary: b,
+		contains: func(p Point) bool { return boundaryContains(b, p) },
+		edges:    getBoundaryEdges(b),
+	})
+}
+
+func (g *geofenceRegistry) AddPolygon(id string, p Polygon) error {
+
+	if p == nil {
+		return ErrInvalidFence
+	}
+
+	return g.AddMultiPolygon(id, NewMultiPolygon(p))
+}
+
+func (g *geofenceRegistry) AddMultiPolygon(id string, m MultiPolygon) error {
+
+	if m == nil {
+		return ErrInvalidFence
+	}
+
+	edges := [][]Point{}
+
+	for _, p := range m.Polygons() {
+		edges = append(edges, closeRing(p.Shell()))
+
+		for _, hole := range p.Holes() {
+			edges = append(edges, closeRing(hole))
+		}
+	}
+
+	return g.add(&fence{id: id, boundary: m.Boundary(), contains: m.Contains, edges: edges})
+}
+
+func (g *geofenceRegistry) Remove(id string) bool {
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	f, ok := g.fences[id]
+
+	if !ok {
+		return false
+	}
+
+	cells := g.cells[f.precision]
+
+	for _, key := range f.cells {
+		if delete(cells[key], id); len(cells[key]) == 0 {
+			delete(cells, key)
+		}
+	}
+
+	if len(cells) == 0 {
+		delete(g.cells, f.precision)
+	}
+
+	for objectID, stays := range g.objects {
+		if delete(stays, id); len(stays) == 0 {
+			delete(g.objects, objectID)
+		}
+	}
+
+	delete(g.fences, id)
+
+	return true
+}
+
+func (g *geofenceRegistry) Fences() []string {
+
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	ids := make([]string, 0, len(g.fences))
+
+	for id := range g.fences {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+// candidates returns the fences indexed by the geohash cells containing the given point.
+func (g *geofenceRegistry) candidates(p Point) map[string]*fence {
+
+	found := make(map[string]*fence)
+
+	for precision, cells := range g.cells {
+		for id, f := range cells[GetHash(p, precision).Bits()] {
+			found[id] = f
+		}
+	}
+
+	return found
+}
+
+func (g *geofenceRegistry) Update(objectID string, p Point, t time.Time) []GeofenceEvent {
+
+	if p == nil {
+		return nil
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	stays := g.objects[objectID]
+
+	if stays == nil {
+		stays = make(map[string]*stay)
+		g.objects[objectID] = stays
+	}
+
+	event := func(eventType GeofenceEventType, id string) GeofenceEvent {
+		return GeofenceEvent{Type: eventType, FenceID: id, ObjectID: objectID, Point: p, Time: t}
+	}
+
+	exits, others := []GeofenceEvent{}, []GeofenceEvent{}
+
+	// The fences the object is inside are checked even if they aren't around its new location,
+	// since it has to exit them.
+	for id, s := range stays {
+		f := g.fences[id]
+
+		if f.contains(p) || f.edgeDistance(p) <= g.options.Hysteresis {
+			if !s.dwell && g.options.DwellTime > 0 && t.Sub(s.since) >= g.options.DwellTime {
+				s.dwell = true
+				others = append(others, event(GeofenceDwell, id))
+			}
+			continue
+		}
+
+		delete(stays, id)
+		exits = append(exits, event(GeofenceExit, id))
+	}
+
+	for id, f := range g.candidates(p) {
+		if _, ok := stays[id]; ok || !f.contains(p) {
+			continue
+		}
+
+		stays[id] = &stay{since: t}
+		others = append(others, event(GeofenceEnter, id))
+	}
+
+	if len(stays) == 0 {
+		delete(g.objects, objectID)
+	}
+
+	sortEvents := func(events []GeofenceEvent) {
+		sort.Slice(events, func(i, j int) bool {
+			if events[i].FenceID != events[j].FenceID {
+				return events[i].FenceID < events[j].FenceID
+			}
+			return events[i].Type < events[j].Type
+		})
+	}
+
+	sortEvents(exits)
+	sortEvents(others)
+
+	return append(exits, others...)
+}
+
+func (g *geofenceRegistry) Inside(objectID string) []string {
+
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	ids := []string{}
+
+	for id := range g.objects[objectID] {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+func (g *geofenceRegistry) Forget(objectID string) {
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	delete(g.objects, objectID)
+}
+
+// NewGeofenceRegistry creates a new empty geofence registry with the given options.
+func NewGeofenceRegistry(options GeofenceOptions) GeofenceRegistry {
+	return &geofenceRegistry{
+		options: options,
+		fences:  make(map[string]*fence),
+		cells:   make(map[uint8]map[uint64]map[string]*fence),
+		objects: make(map[string]map[string]*stay),
+	}
+}
+
+// getBoundaryEdges returns the rings making up the edges of the given boundary, which are the rings of its polygon,
+// or the parallels bounding it if it goes around earth.
+func getBoundaryEdges(b Boundary) [][]Point {
+
+	if p := NewBoundaryPolygon(b); p != nil {
+		return [][]Point{closeRing(p.Shell())}
+	}
+
+	edges := [][]Point{}
+
+	for _, lat := range []float64{b.Lower().Latitude(), b.Upper().Latitude()} {
+		if math.Abs(lat) == NorthPoleLat {
+			continue
+		}
+
+		// Splitting the parallel into arcs of a degree of longitude to follow it closely.
+		ring := make([]Point, 0, int(TotalLongitude)+1)
+
+		for lng := -HalfLongitude; lng <= HalfLongitude; lng++ {
+			ring = append(ring, NewPoint(lat, lng))
+		}
+
+		edges = append(edges, ring)
+	}
+
+	return edges
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getEventTypes(events []GeofenceEvent) []string {
+	types := []string{}
+	for _, e := range events {
+		types = append(types, fmt.Sprintf("%v:%v", e.Type, e.FenceID))
+	}
+	return types
+}
+
+func TestGeofenceEventType(t *testing.T) {
+	assert.Equal(t, "enter", GeofenceEnter.String())
+	assert.Equal(t, "exit", GeofenceExit.String())
+	assert.Equal(t, "dwell", GeofenceDwell.String())
+	assert.Equal(t, "GeofenceEventType(7)", GeofenceEventType(7).String())
+}
+
+func TestGeofenceRegistry_Add(t *testing.T) {
+
+	g := NewGeofenceRegistry(GeofenceOptions{})
+
+	assert.Nil(t, g.AddPolygon("b", NewPolygon(getSquare(0, 0, 1))))
+	assert.Nil(t, g.AddBoundary("a", NewBoundary(NewPoint(10, 10), NewPoint(11, 11))))
+	assert.Nil(t, g.AddMultiPolygon("c", NewMultiPolygon(NewPolygon(getSquare(20, 20, 1)), NewPolygon(getSquare(30, 30, 1)))))
+
+	assert.Equal(t, ErrDuplicateFence, g.AddPolygon("a", NewPolygon(getSquare(0, 0, 1))))
+	assert.Equal(t, ErrInvalidFence, g.AddPolygon("", NewPolygon(getSquare(0, 0, 1))))
+	assert.Equal(t, ErrInvalidFence, g.AddPolygon("d", nil))
+	assert.Equal(t, ErrInvalidFence, g.AddPolygon("d", NewPolygon(nil)))
+	assert.Equal(t, ErrInvalidFence, g.AddBoundary("d", nil))
+	assert.Equal(t, ErrInvalidFence, g.AddMultiPolygon("d", nil))
+
+	assert.Equal(t, []string{"a", "b", "c"}, g.Fences())
+
+	assert.True(t, g.Remove("a"))
+	assert.False(t, g.Remove("a"))
+	assert.Equal(t, []string{"b", "c"}, g.Fences())
+
+	// Removing the last fence an object is inside of forgets the object.
+	now := time.Now()
+	g.Update("car", NewPoint(0, 0), now)
+	g.Update("bus", NewPoint(20, 20), now)
+	assert.Len(t, g.(*geofenceRegistry).objects, 2)
+
+	assert.True(t, g.Remove("b"))
+	assert.Empty(t, g.Inside("car"))
+	assert.Len(t, g.(*geofenceRegistry).objects, 1)
+	assert.NotContains(t, g.(*geofenceRegistry).objects, "car")
+	assert.Equal(t, []string{"c"}, g.Inside("bus"))
+}
+
+func TestGeofenceRegistry_Update(t *testing.T) {
+
+	g := NewGeofenceRegistry(GeofenceOptions{})
+	now := time.Now()
+
+	assert.Nil(t, g.AddPolygon("square", NewPolygon(getSquare(0, 0, 1))))
+	assert.Nil(t, g.AddMultiPolygon("pair", NewMultiPolygon(NewPolygon(getSquare(0, 0, 2)), NewPolygon(getSquare(5, 5, 1)))))
+	assert.Nil(t, g.AddBoundary("box", NewBoundary(NewPoint(5, 5), NewPoint(6, 6))))
+
+	assert.Nil(t, g.Update("car", nil, now))
+	assert.Empty(t, g.Update("car", NewPoint(-1, -1), now))
+	assert.Equal(t, []string{"enter:pair", "enter:square"}, getEventTypes(g.Update("car", NewPoint(0.5, 0.5), now)))
+	assert.Equal(t, []string{"pair", "square"}, g.Inside("car"))
+	assert.Empty(t, g.Update("car", NewPoint(0.6, 0.6), now))
+	assert.Equal(t, []string{"exit:square"}, getEventTypes(g.Update("car", NewPoint(1.5, 1.5), now)))
+
+	events := g.Update("car", NewPoint(5.5, 5.5), now)
+	// Moving between the polygons of a fence keeps the object inside it.
+	assert.Equal(t, []string{"enter:box"}, getEventTypes(events))
+	assert.Equal(t, "car", events[0].ObjectID)
+	assert.Equal(t, now, events[0].Time)
+	assert.Equal(t, NewPoint(5.5, 5.5), events[0].Point)
+
+	// Other objects have their own state.
+	assert.Equal(t, []string{"enter:pair", "enter:square"}, getEventTypes(g.Update("bike", NewPoint(0.5, 0.5), now)))
+	assert.Equal(t, []string{"box", "pair"}, g.Inside("car"))
+
+	// Removing a fence drops the objects out of it silently.
+	assert.True(t, g.Remove("box"))
+	assert.Equal(t, []string{"pair"}, g.Inside("car"))
+	assert.Empty(t, g.Update("car", NewPoint(5.6, 5.6), now))
+
+	g.Forget("car")
+	assert.Empty(t, g.Inside("car"))
+	assert.Equal(t, []string{"enter:pair"}, getEventTypes(g.Update("car", NewPoint(5.6, 5.6), now)))
+}
+
+func TestGeofenceRegistry_Hysteresis(t *testing.T) {
+
+	g := NewGeofenceRegistry(GeofenceOptions{Hysteresis: 1})
+	now := time.Now()
+
+	assert.Nil(t, g.AddPolygon("square", NewPolygon(getSquare(0, 0, 1))))
+
+	assert.Equal(t, []string{"enter:square"}, getEventTypes(g.Update("car", NewPoint(0.5, 0.999), now)))
+
+	// Jittering about 0.5km around the edge.
+	assert.Empty(t, g.Update("car", NewPoint(0.5, 1.004), now))
+	assert.Empty(t, g.Update("car", NewPoint(0.5, 0.996), now))
+	assert.Empty(t, g.Update("car", NewPoint(0.5, 1.004), now))
+
+	assert.Equal(t, []string{"exit:square"}, getEventTypes(g.Update("car", NewPoint(0.5, 1.01), now)))
+	assert.Empty(t, g.Update("car", NewPoint(0.5, 1.004), now))
+	assert.Equal(t, []string{"enter:square"}, getEventTypes(g.Update("car", NewPoint(0.5, 0.999), now)))
+}
+
+func TestGeofenceRegistry_Dwell(t *testing.T) {
+
+	g := NewGeofenceRegistry(GeofenceOptions{DwellTime: time.Minute})
+	now := time.Now()
+
+	assert.Nil(t, g.AddBoundary("box", NewBoundary(NewPoint(0, 0), NewPoint(1, 1))))
+
+	assert.Equal(t, []string{"enter:box"}, getEventTypes(g.Update("car", NewPoint(0.5, 0.5), now)))
+	assert.Empty(t, g.Update("car", NewPoint(0.5, 0.5), now.Add(30*time.Second)))
+	assert.Equal(t, []string{"dwell:box"}, getEventTypes(g.Update("car", NewPoint(0.5, 0.5), now.Add(time.Minute))))
+	assert.Empty(t, g.Update("car", NewPoint(0.5, 0.5), now.Add(2*time.Minute)))
+
+	// Dwelling starts over with each stay.
+	assert.Equal(t, []string{"exit:box"}, getEventTypes(g.Update("car", NewPoint(2, 2), now.Add(3*time.Minute))))
+	assert.Equal(t, []string{"enter:box"}, getEventTypes(g.Update("car", NewPoint(0.5, 0.5), now.Add(4*time.Minute))))
+	assert.Empty(t, g.Update("car", NewPoint(0.5, 0.5), now.Add(4*time.Minute+30*time.Second)))
+	assert.Equal(t, []string{"dwell:box"}, getEventTypes(g.Update("car", NewPoint(0.5, 0.5), now.Add(5*time.Minute))))
+}
+
+func TestGeofenceRegistry_Special(t *testing.T) {
+
+	g := NewGeofenceRegistry(GeofenceOptions{Hysteresis: 1})
+	now := time.Now()
+
+	// Crossing the antimeridian, around the north pole and going around earth.
+	assert.Nil(t, g.AddBoundary("antimeridian", NewBoundary(NewPoint(0, 179), NewPoint(1, -179))))
+	assert.Nil(t, g.AddBoundary("arctic", NewBoundary(NewPoint(80, 0), NewPoint(90, 0))))
+	assert.Nil(t, g.AddBoundary("tropics", &boundary{lower: &point{latitude: -23, longitude: -HalfLongitude}, upper: &point{latitude: 23, longitude: HalfLongitude}}))
+
+	assert.Equal(t, []string{"enter:antimeridian", "enter:tropics"}, getEventTypes(g.Update("ship", NewPoint(0.5, -179.5), now)))
+	assert.Empty(t, g.Update("ship", NewPoint(0.5, 179.5), now))
+	assert.Equal(t, []string{"exit:antimeridian"}, getEventTypes(g.Update("ship", NewPoint(0.5, 170), now)))
+	assert.Equal(t, []string{"exit:tropics", "enter:arctic"}, getEventTypes(g.Update("ship", NewPoint(85, 100), now)))
+	assert.Empty(t, g.Update("ship", NewPoint(90, 0), now))
+	assert.Empty(t, g.Update("ship", NewPoint(80, -100), now))
+	assert.Equal(t, []string{"exit:arctic"}, getEventTypes(g.Update("ship", NewPoint(79, -100), now)))
+}
+
+func TestGeofenceRegistry_Concurrency(t *testing.T) {
+
+	g := NewGeofenceRegistry(GeofenceOptions{})
+	now := time.Now()
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			id := fmt.Sprintf("fence-%d", i)
+			object := fmt.Sprintf("object-%d", i)
+			lat := float64(i * 2)
+
+			assert.Nil(t, g.AddPolygon(id, NewPolygon(getSquare(lat, 0, 1))))
+			assert.Equal(t, []string{"enter:" + id}, getEventTypes(g.Update(object, NewPoint(lat+0.5, 0.5), now)))
+			assert.Equal(t, []string{id}, g.Inside(object))
+			assert.Equal(t, []string{"exit:" + id}, getEventTypes(g.Update(object, NewPoint(lat+0.5, 10), now)))
+		}(i)
+	}
+
+	wg.Wait()
+
+	assert.Len(t, g.Fences(), 10)
+}
@@ -1,8 +1,13 @@
 module github.com/adzr/geo
 
+go 1.18
+
 require (
 	github.com/adzr/mathex v0.0.0-20180929103943-a1e7eaf3798f
+	github.com/stretchr/testify v1.2.2
+)
+
+require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.2.2
 )
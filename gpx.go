@@ -0,0 +1,649 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GPXNamespace is the XML namespace of GPX 1.1 documents.
+const GPXNamespace = "http://www.topografix.com/GPX/1/1"
+
+// GPX is a GPX 1.1 document, holding waypoints, routes and tracks.
+// The metadata and the extensions are kept as raw XML, so that reading and writing a document back keeps them.
+type GPX struct {
+	// Creator is the name of the software that created the document.
+	Creator string
+	// Attributes holds the other attributes of the document root, such as the namespace declarations used by
+	// the extensions, written back as they are.
+	Attributes []xml.Attr
+	// Metadata is the raw XML content of the document metadata element.
+	Metadata []byte
+	// Waypoints are the standalone points of the document.
+	Waypoints []GPXPoint
+	// Routes are the planned paths of the document.
+	Routes []GPXRoute
+	// Tracks are the recorded paths of the document.
+	Tracks []GPXTrack
+	// Extensions is the raw XML content of the document extensions element.
+	Extensions []byte
+}
+
+// GPXPoint is a waypoint, a route point or a track point of a GPX document.
+type GPXPoint struct {
+	// Point is the geo-location point.
+	Point Point
+	// Elevation is the elevation in meters, it's NaN if unknown.
+	Elevation float64
+	// Time is the time the point was recorded at, it's the zero time if unknown.
+	Time time.Time
+	// Name is the name of the point.
+	Name string
+	// Comment is the comment about the point.
+	Comment string
+	// Description is the description of the point.
+	Description string
+	// Symbol is the name of the symbol displaying the point.
+	Symbol string
+	// Type is the classification of the point.
+	Type string
+	// MagneticVariation is the magnetic variation in degrees at the point, it's nil if unknown.
+	MagneticVariation *float64
+	// GeoidHeight is the height in meters of the geoid above the WGS84 ellipsoid at the point, it's nil if unknown.
+	GeoidHeight *float64
+	// Source is the source of the point data, such as the original map.
+	Source string
+	// Links are the links to more information about the point.
+	Links []GPXLink
+	// FixType is the type of the GPS fix, one of none, 2d, 3d, dgps or pps.
+	FixType string
+	// Satellites is the number of satellites used to calculate the fix, it's nil if unknown.
+	Satellites *int
+	// HDOP is the horizontal dilution of precision, it's nil if unknown.
+	HDOP *float64
+	// VDOP is the vertical dilution of precision, it's nil if unknown.
+	VDOP *float64
+	// PDOP is the position dilution of precision, it's nil if unknown.
+	PDOP *float64
+	// DGPSAge is the number of seconds since the last DGPS update, it's nil if unknown.
+	DGPSAge *float64
+	// DGPSID is the identifier of the DGPS station used, it's nil if unknown.
+	DGPSID *int
+	// Extensions is the raw XML content of the point extensions element.
+	Extensions []byte
+}
+
+// GPXLink is a link to an external resource about an element of a GPX document.
+type GPXLink struct {
+	// Href is the URL of the resource.
+	Href string
+	// Text is the text of the link.
+	Text string
+	// Type is the MIME type of the resource.
+	Type string
+}
+
+// Fix returns the point as a fix, where the elevation is the altitude.
+func (p GPXPoint) Fix() Fix {
+	return NewDetailedFix(p.Point, p.Time, math.NaN(), p.Elevation)
+}
+
+// GPXRoute is an ordered list of points leading to a destination.
+type GPXRoute struct {
+	// Name is the name of the route.
+	Name string
+	// Comment is the comment about the route.
+	Comment string
+	// Description is the description of the route.
+	Description string
+	// Source is the source of the route data.
+	Source string
+	// Links are the links to more information about the route.
+	Links []GPXLink
+	// Number is the number of the route, it's nil if unknown.
+	Number *int
+	// Type is the classification of the route.
+	Type string
+	// Points are the route points.
+	Points []GPXPoint
+	// Extensions is the raw XML content of the route extensions element.
+	Extensions []byte
+}
+
+// GPXTrack is an ordered list of segments making a recorded path.
+type GPXTrack struct {
+	// Name is the name of the track.
+	Name string
+	// Comment is the comment about the track.
+	Comment string
+	// Description is the description of the track.
+	Description string
+	// Source is the source of the track data.
+	Source string
+	// Links are the links to more information about the track.
+	Links []GPXLink
+	// Number is the number of the track, it's nil if unknown.
+	Number *int
+	// Type is the classification of the track.
+	Type string
+	// Segments are the continuous parts of the track.
+	Segments []GPXSegment
+	// Extensions is the raw XML content of the track extensions element.
+	Extensions []byte
+}
+
+// Track returns the fixes of all the track segments as a single track.
+func (t GPXTrack) Track() Track {
+
+	fixes := []Fix{}
+
+	for _, s := range t.Segments {
+		for _, p := range s.Points {
+			fixes = append(fixes, p.Fix())
+		}
+	}
+
+	return NewTrack(fixes)
+}
+
+// GPXSegment is a continuous part of a track, recorded without losing the signal.
+type GPXSegment struct {
+	// Points are the track points.
+	Points []GPXPoint
+	// Extensions is the raw XML content of the segment extensions element.
+	Extensions []byte
+}
+
+// gpxRaw is an element kept as raw XML.
+type gpxRaw struct {
+	Content []byte `xml:",innerxml"`
+}
+
+func newGPXRaw(content []byte) *gpxRaw {
+	if len(content) == 0 {
+		return nil
+	}
+	return &gpxRaw{Content: content}
+}
+
+func (r *gpxRaw) content() []byte {
+	if r != nil {
+		return r.Content
+	}
+	return nil
+}
+
+type gpxDocument struct {
+	XMLName    xml.Name   `xml:"gpx"`
+	Version    string     `xml:"version,attr"`
+	Creator    string     `xml:"creator,attr"`
+	Attributes []xml.Attr `xml:",any,attr"`
+	Metadata   *gpxRaw    `xml:"metadata"`
+	Waypoints  []gpxPoint `xml:"wpt"`
+	Routes     []gpxRoute `xml:"rte"`
+	Tracks     []gpxTrack `xml:"trk"`
+	Extensions *gpxRaw    `xml:"extensions"`
+}
+
+type gpxLink struct {
+	Href string `xml:"href,attr"`
+	Text string `xml:"text,omitempty"`
+	Type string `xml:"type,omitempty"`
+}
+
+// The fields follow the order of the elements in the GPX 1.1 schema, which is the order they are written in.
+// The decimals are kept as strings, since encoding/xml would write small numbers with an exponent, which the
+// decimal type of the schema doesn't allow.
+type gpxPoint struct {
+	Latitude          string    `xml:"lat,attr"`
+	Longitude         string    `xml:"lon,attr"`
+	Elevation         string    `xml:"ele,omitempty"`
+	Time              string    `xml:"time,omitempty"`
+	MagneticVariation string    `xml:"magvar,omitempty"`
+	GeoidHeight       string    `xml:"geoidheight,omitempty"`
+	Name              string    `xml:"name,omitempty"`
+	Comment           string    `xml:"cmt,omitempty"`
+	Description       string    `xml:"desc,omitempty"`
+	Source            string    `xml:"src,omitempty"`
+	Links             []gpxLink `xml:"link"`
+	Symbol            string    `xml:"sym,omitempty"`
+	Type              string    `xml:"type,omitempty"`
+	FixType           string    `xml:"fix,omitempty"`
+	Satellites        *int      `xml:"sat"`
+	HDOP              string    `xml:"hdop,omitempty"`
+	VDOP              string    `xml:"vdop,omitempty"`
+	PDOP              string    `xml:"pdop,omitempty"`
+	DGPSAge           string    `xml:"ageofdgpsdata,omitempty"`
+	DGPSID            *int      `xml:"dgpsid"`
+	Extensions        *gpxRaw   `xml:"extensions"`
+}
+
+type gpxRoute struct {
+	Name        string     `xml:"name,omitempty"`
+	Comment     string     `xml:"cmt,omitempty"`
+	Description string     `xml:"desc,omitempty"`
+	Source      string     `xml:"src,omitempty"`
+	Links       []gpxLink  `xml:"link"`
+	Number      *int       `xml:"number"`
+	Type        string     `xml:"type,omitempty"`
+	Extensions  *gpxRaw    `xml:"extensions"`
+	Points      []gpxPoint `xml:"rtept"`
+}
+
+type gpxTrack struct {
+	Name        string       `xml:"name,omitempty"`
+	Comment     string       `xml:"cmt,omitempty"`
+	Description string       `xml:"desc,omitempty"`
+	Source      string       `xml:"src,omitempty"`
+	Links       []gpxLink    `xml:"link"`
+	Number      *int         `xml:"number"`
+	Type        string       `xml:"type,omitempty"`
+	Extensions  *gpxRaw      `xml:"extensions"`
+	Segments    []gpxSegment `xml:"trkseg"`
+}
+
+// gpxLocalTime is the layout of the times without a zone offset, which are taken as UTC.
+const gpxLocalTime = "2006-01-02T15:04:05.999999999"
+
+// parseGPXTime parses the given GPX time, which is expected to be in RFC 3339 format, while a time missing its
+// zone offset is taken as UTC rather than failing the whole document.
+func parseGPXTime(value string) (time.Time, error) {
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+
+	if err != nil {
+		if local, localErr := time.Parse(gpxLocalTime, value); localErr == nil {
+			return local, nil
+		}
+	}
+
+	return t, err
+}
+
+func toGPXLinks(links []gpxLink) []GPXLink {
+
+	if len(links) == 0 {
+		return nil
+	}
+
+	result := make([]GPXLink, 0, len(links))
+
+	for _, l := range links {
+		result = append(result, GPXLink{Href: l.Href, Text: l.Text, Type: l.Type})
+	}
+
+	return result
+}
+
+func newGPXLinks(links []GPXLink) []gpxLink {
+
+	if len(links) == 0 {
+		return nil
+	}
+
+	result := make([]gpxLink, 0, len(links))
+
+	for _, l := range links {
+		result = append(result, gpxLink{Href: l.Href, Text: l.Text, Type: l.Type})
+	}
+
+	return result
+}
+
+type gpxSegment struct {
+	Points     []gpxPoint `xml:"trkpt"`
+	Extensions *gpxRaw    `xml:"extensions"`
+}
+
+// formatGPXDecimal returns the given number as a decimal without an exponent.
+func formatGPXDecimal(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// newGPXDecimal returns the given optional number as a decimal, or an empty string if it's nil.
+func newGPXDecimal(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return formatGPXDecimal(*value)
+}
+
+// gpxDecimals parses the decimals of a point, keeping the first error found.
+type gpxDecimals struct {
+	err error
+}
+
+// parse returns the given decimal, or nil if it's empty.
+func (d *gpxDecimals) parse(value string) *float64 {
+
+	value = strings.TrimSpace(value)
+
+	if value == "" {
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+
+	if err != nil {
+		if d.err == nil {
+			d.err = err
+		}
+		return nil
+	}
+
+	return &v
+}
+
+// number returns the given decimal, or the given default value if it's empty.
+func (d *gpxDecimals) number(value string, unknown float64) float64 {
+	if v := d.parse(value); v != nil {
+		return *v
+	}
+	return unknown
+}
+
+func (p gpxPoint) toPoint() (GPXPoint, error) {
+
+	decimals := &gpxDecimals{}
+
+	result := GPXPoint{
+		Point:             NewPoint(decimals.number(p.Latitude, 0), decimals.number(p.Longitude, 0)),
+		Elevation:         decimals.number(p.Elevation, math.NaN()),
+		Name:              p.Name,
+		Comment:           p.Comment,
+		Description:       p.Description,
+		Symbol:            p.Symbol,
+		Type:              p.Type,
+		MagneticVariation: decimals.parse(p.MagneticVariation),
+		GeoidHeight:       decimals.parse(p.GeoidHeight),
+		Source:            p.Source,
+		Links:             toGPXLinks(p.Links),
+		FixType:           p.FixType,
+		Satellites:        p.Satellites,
+		HDOP:              decimals.parse(p.HDOP),
+		VDOP:              decimals.parse(p.VDOP),
+		PDOP:              decimals.parse(p.PDOP),
+		DGPSAge:           decimals.parse(p.DGPSAge),
+		DGPSID:            p.DGPSID,
+		Extensions:        p.Extensions.content(),
+	}
+
+	if decimals.err != nil {
+		return result, decimals.err
+	}
+
+	if p.Time != "" {
+		t, err := parseGPXTime(p.Time)
+
+		if err != nil {
+			return result, err
+		}
+
+		result.Time = t
+	}
+
+	return result, nil
+}
+
+func newGPXPoint(p GPXPoint) gpxPoint {
+
+	result := gpxPoint{
+		Latitude:          formatGPXDecimal(p.Point.Latitude()),
+		Longitude:         formatGPXDecimal(p.Point.Longitude()),
+		Name:              p.Name,
+		Comment:           p.Comment,
+		Description:       p.Description,
+		Symbol:            p.Symbol,
+		Type:              p.Type,
+		MagneticVariation: newGPXDecimal(p.MagneticVariation),
+		GeoidHeight:       newGPXDecimal(p.GeoidHeight),
+		Source:            p.Source,
+		Links:             newGPXLinks(p.Links),
+		FixType:           p.FixType,
+		Satellites:        p.Satellites,
+		HDOP:              newGPXDecimal(p.HDOP),
+		VDOP:              newGPXDecimal(p.VDOP),
+		PDOP:              newGPXDecimal(p.PDOP),
+		DGPSAge:           newGPXDecimal(p.DGPSAge),
+		DGPSID:            p.DGPSID,
+		Extensions:        newGPXRaw(p.Extensions),
+	}
+
+	if !math.IsNaN(p.Elevation) {
+		result.Elevation = formatGPXDecimal(p.Elevation)
+	}
+
+	if !p.Time.IsZero() {
+		result.Time = p.Time.Format(time.RFC3339Nano)
+	}
+
+	return result
+}
+
+func toGPXPoints(points []gpxPoint) ([]GPXPoint, error) {
+
+	result := make([]GPXPoint, 0, len(points))
+
+	for _, p := range points {
+		point, err := p.toPoint()
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, point)
+	}
+
+	return result, nil
+}
+
+func newGPXPoints(points []GPXPoint) []gpxPoint {
+
+	result := make([]gpxPoint, 0, len(points))
+
+	for _, p := range points {
+		if p.Point != nil {
+			result = append(result, newGPXPoint(p))
+		}
+	}
+
+	return result
+}
+
+// getGPXAttributes returns the given root attributes with their namespaces turned back into the prefixes
+// declared for them, so that they can be written back as they are.
+func getGPXAttributes(attributes []xml.Attr) []xml.Attr {
+
+	prefixes := make(map[string]string)
+
+	for _, a := range attributes {
+		if a.Name.Space == "xmlns" {
+			prefixes[a.Value] = a.Name.Local
+		}
+	}
+
+	result := make([]xml.Attr, 0, len(attributes))
+
+	for _, a := range attributes {
+		name := a.Name.Local
+
+		switch {
+		case a.Name.Space == "xmlns":
+			name = "xmlns:" + name
+		case a.Name.Space != "":
+			prefix, ok := prefixes[a.Name.Space]
+
+			if !ok {
+				continue
+			}
+
+			name = prefix + ":" + name
+		case name == "xmlns":
+			// The default namespace is always written as GPXNamespace.
+			continue
+		}
+
+		result = append(result, xml.Attr{Name: xml.Name{Local: name}, Value: a.Value})
+	}
+
+	return result
+}
+
+// ReadGPX reads a GPX 1.1 document from the given reader, keeping all the standard elements of the points,
+// routes and tracks.
+// The times are expected in RFC 3339 format, while a time missing its zone offset is taken as UTC.
+func ReadGPX(r io.Reader) (*GPX, error) {
+
+	var doc gpxDocument
+
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	g := &GPX{
+		Creator:    doc.Creator,
+		Attributes: getGPXAttributes(doc.Attributes),
+		Metadata:   doc.Metadata.content(),
+		Extensions: doc.Extensions.content(),
+	}
+
+	var err error
+
+	if g.Waypoints, err = toGPXPoints(doc.Waypoints); err != nil {
+		return nil, err
+	}
+
+	for _, rte := range doc.Routes {
+		route := GPXRoute{
+			Name:        rte.Name,
+			Comment:     rte.Comment,
+			Description: rte.Description,
+			Source:      rte.Source,
+			Links:       toGPXLinks(rte.Links),
+			Number:      rte.Number,
+			Type:        rte.Type,
+			Extensions:  rte.Extensions.content(),
+		}
+
+		if route.Points, err = toGPXPoints(rte.Points); err != nil {
+			return nil, err
+		}
+
+		g.Routes = append(g.Routes, route)
+	}
+
+	for _, trk := range doc.Tracks {
+		track := GPXTrack{
+			Name:        trk.Name,
+			Comment:     trk.Comment,
+			Description: trk.Description,
+			Source:      trk.Source,
+			Links:       toGPXLinks(trk.Links),
+			Number:      trk.Number,
+			Type:        trk.Type,
+			Extensions:  trk.Extensions.content(),
+		}
+
+		for _, seg := range trk.Segments {
+			segment := GPXSegment{Extensions: seg.Extensions.content()}
+
+			if segment.Points, err = toGPXPoints(seg.Points); err != nil {
+				return nil, err
+			}
+
+			track.Segments = append(track.Segments, segment)
+		}
+
+		g.Tracks = append(g.Tracks, track)
+	}
+
+	return g, nil
+}
+
+// WriteGPX writes the given GPX document to the given writer as GPX 1.1, skipping the points with nil
+// geo-location points, where a nil document is written as an empty one.
+func WriteGPX(w io.Writer, g *GPX) error {
+
+	if g == nil {
+		g = &GPX{}
+	}
+
+	doc := gpxDocument{
+		Version:    "1.1",
+		Creator:    g.Creator,
+		Attributes: append([]xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: GPXNamespace}}, g.Attributes...),
+		Metadata:   newGPXRaw(g.Metadata),
+		Waypoints:  newGPXPoints(g.Waypoints),
+		Extensions: newGPXRaw(g.Extensions),
+	}
+
+	for _, route := range g.Routes {
+		doc.Routes = append(doc.Routes, gpxRoute{
+			Name:        route.Name,
+			Comment:     route.Comment,
+			Description: route.Description,
+			Source:      route.Source,
+			Links:       newGPXLinks(route.Links),
+			Number:      route.Number,
+			Type:        route.Type,
+			Extensions:  newGPXRaw(route.Extensions),
+			Points:      newGPXPoints(route.Points),
+		})
+	}
+
+	for _, track := range g.Tracks {
+		trk := gpxTrack{
+			Name:        track.Name,
+			Comment:     track.Comment,
+			Description: track.Description,
+			Source:      track.Source,
+			Links:       newGPXLinks(track.Links),
+			Number:      track.Number,
+			Type:        track.Type,
+			Extensions:  newGPXRaw(track.Extensions),
+		}
+
+		for _, segment := range track.Segments {
+			trk.Segments = append(trk.Segments, gpxSegment{
+				Points:     newGPXPoints(segment.Points),
+				Extensions: newGPXRaw(segment.Extensions),
+			})
+		}
+
+		doc.Tracks = append(doc.Tracks, trk)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
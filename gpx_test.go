@@ -0,0 +1,270 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1" creator="Garmin Connect"
+  xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1"
+  xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+  xsi:schemaLocation="http://www.topografix.com/GPX/1/1 http://www.topografix.com/GPX/1/1/gpx.xsd">
+  <metadata><name>Morning Ride</name><time>2018-05-01T08:00:00Z</time></metadata>
+  <wpt lat="45.5" lon="-122.25"><ele>12.5</ele><name>Depot</name><sym>Flag</sym></wpt>
+  <rte>
+    <name>Plan</name>
+    <rtept lat="45.5" lon="-122.25"></rtept>
+    <rtept lat="45.6" lon="-122.3"><name>Stop</name></rtept>
+  </rte>
+  <trk>
+    <name>Ride</name>
+    <type>cycling</type>
+    <trkseg>
+      <trkpt lat="45.5" lon="-122.25">
+        <ele>10</ele>
+        <time>2018-05-01T08:00:00Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>120</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions>
+      </trkpt>
+      <trkpt lat="45.51" lon="-122.25">
+        <ele>11</ele>
+        <time>2018-05-01T08:01:00Z</time>
+      </trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="45.52" lon="-122.25"><time>2018-05-01T08:05:00Z</time></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+func TestReadGPX(t *testing.T) {
+
+	g, err := ReadGPX(strings.NewReader(testGPX))
+	assert.Nil(t, err)
+	assert.Equal(t, "Garmin Connect", g.Creator)
+	assert.Contains(t, string(g.Metadata), "<name>Morning Ride</name>")
+
+	assert.Len(t, g.Waypoints, 1)
+	assert.Equal(t, NewPoint(45.5, -122.25), g.Waypoints[0].Point)
+	assert.Equal(t, 12.5, g.Waypoints[0].Elevation)
+	assert.Equal(t, "Depot", g.Waypoints[0].Name)
+	assert.Equal(t, "Flag", g.Waypoints[0].Symbol)
+	assert.True(t, g.Waypoints[0].Time.IsZero())
+
+	assert.Len(t, g.Routes, 1)
+	assert.Equal(t, "Plan", g.Routes[0].Name)
+	assert.Len(t, g.Routes[0].Points, 2)
+	assert.True(t, math.IsNaN(g.Routes[0].Points[0].Elevation))
+	assert.Equal(t, "Stop", g.Routes[0].Points[1].Name)
+
+	assert.Len(t, g.Tracks, 1)
+	trk := g.Tracks[0]
+	assert.Equal(t, "Ride", trk.Name)
+	assert.Equal(t, "cycling", trk.Type)
+	assert.Len(t, trk.Segments, 2)
+	assert.Len(t, trk.Segments[0].Points, 2)
+	assert.Equal(t, time.Date(2018, 5, 1, 8, 1, 0, 0, time.UTC), trk.Segments[0].Points[1].Time)
+	assert.Contains(t, string(trk.Segments[0].Points[0].Extensions), "<gpxtpx:hr>120</gpxtpx:hr>")
+
+	tk := trk.Track()
+	assert.Len(t, tk.Fixes(), 3)
+	assert.Equal(t, 5*time.Minute, tk.Duration())
+	altitude, ok := tk.Fixes()[0].Altitude()
+	assert.True(t, ok)
+	assert.Equal(t, 10.0, altitude)
+
+	_, err = ReadGPX(strings.NewReader("<gpx><wpt lat=\"1\" lon=\"2\"><time>yesterday</time></wpt></gpx>"))
+	assert.NotNil(t, err)
+
+	_, err = ReadGPX(strings.NewReader("<gpx>"))
+	assert.NotNil(t, err)
+}
+
+func TestWriteGPX(t *testing.T) {
+
+	g, err := ReadGPX(strings.NewReader(testGPX))
+	assert.Nil(t, err)
+
+	var b bytes.Buffer
+	assert.Nil(t, WriteGPX(&b, g))
+
+	out := b.String()
+	assert.True(t, strings.HasPrefix(out, "<?xml"))
+	assert.Contains(t, out, `xmlns="http://www.topografix.com/GPX/1/1"`)
+	assert.Contains(t, out, `xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1"`)
+	assert.Contains(t, out, `xsi:schemaLocation="http://www.topografix.com/GPX/1/1 http://www.topografix.com/GPX/1/1/gpx.xsd"`)
+	assert.Contains(t, out, "<gpxtpx:hr>120</gpxtpx:hr>")
+	assert.Contains(t, out, "<time>2018-05-01T08:00:00Z</time>")
+
+	// Reading the written document back gives the same document.
+	again, err := ReadGPX(strings.NewReader(out))
+	assert.Nil(t, err)
+	assert.Equal(t, g.Attributes, again.Attributes)
+	assert.Equal(t, g.Metadata, again.Metadata)
+	// Unknown elevations are NaN, which are never equal.
+	assert.Equal(t, g.Tracks[0].Segments[0], again.Tracks[0].Segments[0])
+	assert.Equal(t, g.Tracks[0].Segments[1].Points[0].Time, again.Tracks[0].Segments[1].Points[0].Time)
+	assert.True(t, math.IsNaN(again.Tracks[0].Segments[1].Points[0].Elevation))
+	assert.Equal(t, g.Waypoints[0], again.Waypoints[0])
+	assert.Equal(t, g.Routes[0].Points[1].Point, again.Routes[0].Points[1].Point)
+	assert.Equal(t, g.Routes[0].Points[1].Name, again.Routes[0].Points[1].Name)
+
+	// Writing a new document.
+	b.Reset()
+	assert.Nil(t, WriteGPX(&b, &GPX{
+		Creator:   "geo",
+		Waypoints: []GPXPoint{{Point: NewPoint(1, 2), Elevation: math.NaN(), Name: "A"}, {Elevation: 1}},
+	}))
+	assert.Contains(t, b.String(), `<wpt lat="1" lon="2">`)
+	assert.NotContains(t, b.String(), "<ele>")
+	assert.Equal(t, 1, strings.Count(b.String(), "<wpt"))
+}
+
+const testFullGPX = `<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1" creator="Logger">
+  <wpt lat="45.5" lon="-122.25">
+    <time>2018-05-01T08:00:00</time>
+    <magvar>15.5</magvar>
+    <geoidheight>-19.5</geoidheight>
+    <src>Survey</src>
+    <link href="http://example.com/depot"><text>Depot</text><type>text/html</type></link>
+    <link href="http://example.com/depot.jpg"></link>
+    <fix>3d</fix>
+    <sat>0</sat>
+    <hdop>0.9</hdop>
+    <vdop>1.2</vdop>
+    <pdop>1.5</pdop>
+    <ageofdgpsdata>2.5</ageofdgpsdata>
+    <dgpsid>17</dgpsid>
+  </wpt>
+  <rte>
+    <name>Plan</name>
+    <cmt>Short</cmt>
+    <src>Planner</src>
+    <link href="http://example.com/plan"></link>
+    <number>2</number>
+  </rte>
+  <trk>
+    <cmt>Windy</cmt>
+    <src>Watch</src>
+    <number>0</number>
+    <trkseg><trkpt lat="45.5" lon="-122.25"><time>2018-05-01T08:00:00.5+02:00</time><sat>7</sat></trkpt></trkseg>
+  </trk>
+</gpx>`
+
+func TestReadGPX_Standard(t *testing.T) {
+
+	g, err := ReadGPX(strings.NewReader(testFullGPX))
+	assert.Nil(t, err)
+
+	w := g.Waypoints[0]
+	// A time without a zone offset is taken as UTC.
+	assert.Equal(t, time.Date(2018, 5, 1, 8, 0, 0, 0, time.UTC), w.Time)
+	assert.Equal(t, 15.5, *w.MagneticVariation)
+	assert.Equal(t, -19.5, *w.GeoidHeight)
+	assert.Equal(t, "Survey", w.Source)
+	assert.Equal(t, []GPXLink{{Href: "http://example.com/depot", Text: "Depot", Type: "text/html"}, {Href: "http://example.com/depot.jpg"}}, w.Links)
+	assert.Equal(t, "3d", w.FixType)
+	assert.Equal(t, 0, *w.Satellites)
+	assert.Equal(t, 0.9, *w.HDOP)
+	assert.Equal(t, 1.2, *w.VDOP)
+	assert.Equal(t, 1.5, *w.PDOP)
+	assert.Equal(t, 2.5, *w.DGPSAge)
+	assert.Equal(t, 17, *w.DGPSID)
+
+	r := g.Routes[0]
+	assert.Equal(t, "Short", r.Comment)
+	assert.Equal(t, "Planner", r.Source)
+	assert.Equal(t, []GPXLink{{Href: "http://example.com/plan"}}, r.Links)
+	assert.Equal(t, 2, *r.Number)
+
+	trk := g.Tracks[0]
+	assert.Equal(t, "Windy", trk.Comment)
+	assert.Equal(t, "Watch", trk.Source)
+	assert.Equal(t, 0, *trk.Number)
+	assert.Equal(t, 7, *trk.Segments[0].Points[0].Satellites)
+	assert.Nil(t, trk.Segments[0].Points[0].HDOP)
+	assert.True(t, time.Date(2018, 5, 1, 6, 0, 0, 5e8, time.UTC).Equal(trk.Segments[0].Points[0].Time))
+
+	// Reading the written document back loses nothing.
+	var b bytes.Buffer
+	assert.Nil(t, WriteGPX(&b, g))
+
+	again, err := ReadGPX(bytes.NewReader(b.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, g.Routes, again.Routes)
+	assert.Equal(t, w.Links, again.Waypoints[0].Links)
+	assert.Equal(t, w.DGPSID, again.Waypoints[0].DGPSID)
+	assert.Equal(t, w.Satellites, again.Waypoints[0].Satellites)
+	assert.True(t, w.Time.Equal(again.Waypoints[0].Time))
+	assert.True(t, trk.Segments[0].Points[0].Time.Equal(again.Tracks[0].Segments[0].Points[0].Time))
+	assert.Equal(t, trk.Number, again.Tracks[0].Number)
+
+	// The elements are written in the order of the schema.
+	out := b.String()
+	order := []string{"<time>", "<magvar>", "<geoidheight>", "<src>", "<link ", "<fix>", "<sat>", "<hdop>", "<vdop>", "<pdop>", "<ageofdgpsdata>", "<dgpsid>"}
+
+	for i := 1; i < len(order); i++ {
+		assert.True(t, strings.Index(out, order[i-1]) < strings.Index(out, order[i]), order[i])
+	}
+}
+
+func TestWriteGPX_Nil(t *testing.T) {
+
+	var b bytes.Buffer
+	assert.Nil(t, WriteGPX(&b, nil))
+
+	g, err := ReadGPX(bytes.NewReader(b.Bytes()))
+	assert.Nil(t, err)
+	assert.Empty(t, g.Waypoints)
+	assert.Empty(t, g.Routes)
+	assert.Empty(t, g.Tracks)
+}
+
+func TestWriteGPX_Decimals(t *testing.T) {
+
+	hdop := 0.00002
+	g := &GPX{Waypoints: []GPXPoint{{Point: NewPoint(0.00005, -0.00001), Elevation: 0.00003, HDOP: &hdop}}}
+
+	var b bytes.Buffer
+	assert.Nil(t, WriteGPX(&b, g))
+
+	// The schema decimals have no exponent.
+	out := b.String()
+	assert.Contains(t, out, `<wpt lat="0.00005" lon="-0.00001">`)
+	assert.Contains(t, out, "<ele>0.00003</ele>")
+	assert.Contains(t, out, "<hdop>0.00002</hdop>")
+	assert.NotContains(t, out, "e-0")
+
+	again, err := ReadGPX(strings.NewReader(out))
+	assert.Nil(t, err)
+	assert.Equal(t, g.Waypoints[0].Point, again.Waypoints[0].Point)
+	assert.Equal(t, 0.00003, again.Waypoints[0].Elevation)
+	assert.Equal(t, hdop, *again.Waypoints[0].HDOP)
+
+	_, err = ReadGPX(strings.NewReader(`<gpx><wpt lat="1" lon="2"><hdop>low</hdop></wpt></gpx>`))
+	assert.NotNil(t, err)
+
+	_, err = ReadGPX(strings.NewReader(`<gpx><wpt lat="north" lon="2"></wpt></gpx>`))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,310 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+	"strings"
+)
+
+// hashCharBits is the number of geohash bits encoded by each character of its base32 string.
+const hashCharBits = 5
+
+// HashIndex is an in-memory index of values keyed by geo-location points, where the points are kept in a prefix
+// trie of the base32 strings of their geohashes having the maximum precision, so the points inside a geohash cell
+// of any precision are found under a single trie node.
+type HashIndex[T any] interface {
+	// Len returns the number of values in the index.
+	Len() int
+	// Insert adds the given value keyed by the given point, nil points are ignored.
+	Insert(p Point, value T)
+	// Delete removes the first value keyed by a point having the same latlng as the given one, for which
+	// the given function returns true, reporting whether a value was removed.
+	Delete(p Point, match func(value T) bool) bool
+	// Cell calls the given function with each value keyed by a point inside the given geohash cell,
+	// until it returns false.
+	Cell(h Hash, fn func(p Point, value T) bool)
+	// Search calls the given function with each value keyed by a point inside the given boundary,
+	// until it returns false.
+	Search(b Boundary, fn func(p Point, value T) bool)
+	// Radius calls the given function with each value keyed by a point within the given distance in kilometers,
+	// as measured by GetDistance, from the given center, along with that distance, until it returns false.
+	Radius(center Point, radiusInKM float64, fn func(p Point, value T, distanceInKM float64) bool)
+}
+
+type hashIndexEntry[T any] struct {
+	point Point
+	value T
+}
+
+// hashTrieNode is a node of the geohash prefix trie, having a child for each base32 character, where the nodes at
+// the depth of the maximum precision hold the entries.
+type hashTrieNode[T any] struct {
+	children [1 << hashCharBits]*hashTrieNode[T]
+	entries  []hashIndexEntry[T]
+}
+
+// walk calls the given function with each entry under the node until it returns false.
+func (n *hashTrieNode[T]) walk(fn func(e hashIndexEntry[T]) bool) bool {
+
+	for _, e := range n.entries {
+		if !fn(e) {
+			return false
+		}
+	}
+
+	for _, child := range n.children {
+		if child != nil && !child.walk(fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type hashIndex[T any] struct {
+	root  *hashTrieNode[T]
+	count int
+}
+
+func (x *hashIndex[T]) Len() int {
+	return x.count
+}
+
+func (x *hashIndex[T]) Insert(p Point, value T) {
+
+	if p == nil {
+		return
+	}
+
+	n := x.root
+
+	for _, c := range GetHash(p, MaxHashBits).String() {
+		i := strings.IndexRune(base32, c)
+
+		if n.children[i] == nil {
+			n.children[i] = &hashTrieNode[T]{}
+		}
+
+		n = n.children[i]
+	}
+
+	n.entries = append(n.entries, hashIndexEntry[T]{point: p, value: value})
+	x.count++
+}
+
+func (x *hashIndex[T]) Delete(p Point, match func(value T) bool) bool {
+
+	if p == nil {
+		return false
+	}
+
+	path := []*hashTrieNode[T]{x.root}
+	keys := []int{}
+
+	for _, c := range GetHash(p, MaxHashBits).String() {
+		i := strings.IndexRune(base32, c)
+		n := path[len(path)-1].children[i]
+
+		if n == nil {
+			return false
+		}
+
+		path = append(path, n)
+		keys = append(keys, i)
+	}
+
+	leaf := path[len(path)-1]
+
+	for i, e := range leaf.entries {
+		if e.point.Latitude() != p.Latitude() || e.point.Longitude() != p.Longitude() {
+			continue
+		}
+
+		if match != nil && !match(e.value) {
+			continue
+		}
+
+		leaf.entries = append(leaf.entries[:i], leaf.entries[i+1:]...)
+		x.count--
+
+		// Pruning the nodes left without any entries under them.
+		for d := len(path) - 1; d > 0 && path[d].empty(); d-- {
+			path[d-1].children[keys[d-1]] = nil
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func (n *hashTrieNode[T]) empty() bool {
+
+	if len(n.entries) > 0 {
+		return false
+	}
+
+	for _, child := range n.children {
+		if child != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cell calls the given function with each entry inside the geohash cell having the given bits and size,
+// reporting whether the function never returned false.
+// Since each character of the trie encodes five bits, the bits left after the last whole character select the
+// children having them as the leading bits of their characters.
+func (x *hashIndex[T]) cell(bits uint64, size uint8, fn func(e hashIndexEntry[T]) bool) bool {
+
+	n := x.root
+
+	for i := uint8(0); i+hashCharBits <= size; i += hashCharBits {
+		if n = n.children[(bits>>(64-i-hashCharBits))&bitMask]; n == nil {
+			return true
+		}
+	}
+
+	rest := size % hashCharBits
+
+	if rest == 0 {
+		return n.walk(fn)
+	}
+
+	lead := (bits >> (64 - size)) & (1<<rest - 1)
+
+	for c, child := range n.children {
+		if child != nil && uint64(c)>>(hashCharBits-rest) == lead && !child.walk(fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (x *hashIndex[T]) Cell(h Hash, fn func(p Point, value T) bool) {
+
+	if h == nil {
+		return
+	}
+
+	x.cell(h.Bits(), h.Size(), func(e hashIndexEntry[T]) bool {
+		return fn(e.point, e.value)
+	})
+}
+
+// query calls the given function with each entry inside the geohash cells covering the given latlng range,
+// using the highest precision not exceeding the given one that keeps the number of cells reasonable.
+func (x *hashIndex[T]) query(minLat, maxLat, minLng, maxLng float64, precision uint8, fn func(e hashIndexEntry[T]) bool) {
+
+	precision = getCoveringPrecision(minLat, maxLat, minLng, maxLng, precision)
+
+	for _, h := range getCoveringHashes(minLat, maxLat, minLng, maxLng, precision) {
+		if !x.cell(h.Bits(), h.Size(), fn) {
+			return
+		}
+	}
+}
+
+func (x *hashIndex[T]) Search(b Boundary, fn func(p Point, value T) bool) {
+
+	if b == nil {
+		return
+	}
+
+	minLng, maxLng := getBoundaryLngRange(b)
+
+	x.query(b.Lower().Latitude(), b.Upper().Latitude(), minLng, maxLng, MaxHashBits, func(e hashIndexEntry[T]) bool {
+		return !boundaryContains(b, e.point) || fn(e.point, e.value)
+	})
+}
+
+func (x *hashIndex[T]) Radius(center Point, radiusInKM float64, fn func(p Point, value T, distanceInKM float64) bool) {
+
+	if center == nil || !(radiusInKM >= 0) {
+		return
+	}
+
+	minLat, maxLat, minLng, maxLng := getDistanceRange(center, radiusInKM+distanceMargin)
+
+	x.query(minLat, maxLat, minLng, maxLng, getRadiusPrecision(radiusInKM), func(e hashIndexEntry[T]) bool {
+		d := GetDistance(center, e.point)
+		return d > radiusInKM || fn(e.point, e.value, d)
+	})
+}
+
+// NewHashIndex creates a new empty geohash prefix index.
+func NewHashIndex[T any]() HashIndex[T] {
+	return &hashIndex[T]{root: &hashTrieNode[T]{}}
+}
+
+// getDistanceRange returns a latlng range containing all the geo-location points within the given distance in
+// kilometers from the given center, as measured by GetDistance, where the longitude range is not normalized.
+// The range is derived from the haversine formula used by GetDistance rather than the geometry of the sphere,
+// so it holds with the approximate degree to radian conversion it uses as well.
+func getDistanceRange(center Point, radiusInKM float64) (minLat, maxLat, minLng, maxLng float64) {
+
+	angle := math.Min(radiusInKM/EarthRadiusInKM, math.Pi)
+	haversine := math.Pow(math.Sin(angle/2), 2)
+
+	// The approximate conversion makes the cosine of the latitudes within a few kilometers of the poles negative,
+	// where the longitude term of the formula is negative too, letting the latitude term exceed the haversine
+	// of the distance by as much as that term.
+	cosCenter := math.Cos(center.Latitude() * DegToRad)
+	cosPole := math.Cos(NorthPoleLat * DegToRad)
+	limit := math.Pi / 2 / DegToRad
+
+	latRange := func(slack float64) (float64, float64) {
+		dLat := NorthPoleLat - SouthPoleLat
+
+		// The haversine of the latitude difference is not increasing anymore right before 180 degrees.
+		if sinHalf := math.Sqrt(haversine + slack); sinHalf < math.Sin(dLat*DegToRad/2) {
+			dLat = 2 * math.Asin(sinHalf) / DegToRad
+		}
+
+		return math.Max(SouthPoleLat, center.Latitude()-dLat), math.Min(NorthPoleLat, center.Latitude()+dLat)
+	}
+
+	if cosCenter <= 0 {
+		minLat, maxLat = latRange(-cosCenter)
+	} else if minLat, maxLat = latRange(-cosCenter * cosPole); minLat >= -limit && maxLat <= limit {
+		// No point having a negative cosine can be within the distance.
+		minLat, maxLat = latRange(0)
+	}
+
+	// The longitude term alone can't exceed the haversine of the distance either, as long as it's scaled by the
+	// positive cosines of both latitudes.
+	cosLat := cosCenter * math.Min(math.Cos(minLat*DegToRad), math.Cos(maxLat*DegToRad))
+
+	if cosCenter <= 0 || cosLat <= 0 {
+		return minLat, maxLat, -HalfLongitude, HalfLongitude
+	}
+
+	sinHalf := math.Sqrt(haversine / cosLat)
+
+	// The haversine of the longitude difference is not increasing anymore right before 180 degrees.
+	if sinHalf >= math.Sin(HalfLongitude*DegToRad/2) {
+		return minLat, maxLat, -HalfLongitude, HalfLongitude
+	}
+
+	dLng := 2 * math.Asin(sinHalf) / DegToRad
+
+	return minLat, maxLat, center.Longitude() - dLng, center.Longitude() + dLng
+}
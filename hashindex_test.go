@@ -0,0 +1,210 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getRandomPoint(r *rand.Rand) Point {
+	switch r.Intn(4) {
+	case 0:
+		// Close to the poles.
+		return NewPoint((90-r.Float64()*0.5)*float64(1-2*r.Intn(2)), r.Float64()*360-180)
+	case 1:
+		// Close to the antimeridian.
+		return NewPoint(r.Float64()*180-90, 180-r.Float64()*2)
+	}
+	return NewPoint(r.Float64()*180-90, r.Float64()*360-180)
+}
+
+func getHashIndexResults(fn func(fn func(p Point, value int) bool)) []int {
+	found := []int{}
+	fn(func(_ Point, v int) bool {
+		found = append(found, v)
+		return true
+	})
+	sort.Ints(found)
+	return found
+}
+
+func TestGetDistanceRange(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100000; i++ {
+		center, p := getRandomPoint(r), getRandomPoint(r)
+
+		if i%2 == 0 {
+			p = NewPoint(center.Latitude()+r.NormFloat64(), center.Longitude()+r.NormFloat64())
+		}
+
+		d := GetDistance(center, p)
+		minLat, maxLat, minLng, maxLng := getDistanceRange(center, d+distanceMargin)
+		lng := p.Longitude()
+
+		for lng < minLng {
+			lng += TotalLongitude
+		}
+
+		for lng >= minLng+TotalLongitude {
+			lng -= TotalLongitude
+		}
+
+		assert.True(t, p.Latitude() >= minLat && p.Latitude() <= maxLat, "%v %v %v", center, p, d)
+		assert.True(t, math.Abs(p.Latitude()) == NorthPoleLat || lng <= maxLng, "%v %v %v", center, p, d)
+	}
+
+	minLat, maxLat, minLng, maxLng := getDistanceRange(NewPoint(0, 0), 111.2)
+	assert.InDelta(t, -1, minLat, 0.01)
+	assert.InDelta(t, 1, maxLat, 0.01)
+	assert.InDelta(t, -1, minLng, 0.01)
+	assert.InDelta(t, 1, maxLng, 0.01)
+
+	minLat, maxLat, minLng, maxLng = getDistanceRange(NewPoint(89.5, 0), 111.2)
+	assert.InDelta(t, 88.5, minLat, 0.05)
+	assert.Equal(t, []float64{90, -180, 180}, []float64{maxLat, minLng, maxLng})
+}
+
+func TestHashIndex(t *testing.T) {
+
+	x := NewHashIndex[int]()
+
+	x.Insert(nil, 0)
+	x.Insert(NewPoint(1, 1), 1)
+	x.Insert(NewPoint(1, 1), 2)
+	x.Insert(NewPoint(10, 179.9), 3)
+	x.Insert(NewPoint(10, -179.9), 4)
+	x.Insert(NewPoint(90, 0), 5)
+
+	assert.Equal(t, 5, x.Len())
+
+	assert.Equal(t, []int{1, 2}, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Cell(GetHash(NewPoint(1, 1), 7), fn)
+	}))
+	assert.Equal(t, []int{1, 2}, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Cell(GetHash(NewPoint(1, 1), 10), fn)
+	}))
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Cell(GetHash(NewPoint(1, 1), 0), fn)
+	}))
+	assert.Empty(t, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Cell(GetHash(NewPoint(-1, -1), 7), fn)
+	}))
+	assert.Empty(t, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Cell(nil, fn)
+	}))
+
+	assert.Equal(t, []int{3, 4}, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Search(NewBoundary(NewPoint(9, 179), NewPoint(11, -179)), fn)
+	}))
+	assert.Equal(t, []int{5}, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Search(NewBoundary(NewPoint(89, 0), NewPoint(90, 0)), fn)
+	}))
+	assert.Equal(t, []int{3, 4}, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Radius(NewPoint(10, 180), 20, func(p Point, v int, d float64) bool {
+			assert.InDelta(t, GetDistance(NewPoint(10, 180), p), d, DecimalPrecision)
+			return fn(p, v)
+		})
+	}))
+	assert.Empty(t, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Radius(NewPoint(10, 180), -1, func(p Point, v int, _ float64) bool { return fn(p, v) })
+	}))
+	assert.Empty(t, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Radius(NewPoint(10, 180), math.NaN(), func(p Point, v int, _ float64) bool { return fn(p, v) })
+	}))
+
+	// A zero radius looks up the exact location.
+	assert.Equal(t, []int{1, 2}, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Radius(NewPoint(1, 1), 0, func(p Point, v int, d float64) bool {
+			assert.Equal(t, 0.0, d)
+			return fn(p, v)
+		})
+	}))
+	assert.Equal(t, []int{5}, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Radius(NewPoint(90, 0), 0, func(p Point, v int, _ float64) bool { return fn(p, v) })
+	}))
+	assert.Empty(t, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Radius(NewPoint(1, 1.000001), 0, func(p Point, v int, _ float64) bool { return fn(p, v) })
+	}))
+	assert.Equal(t, []int{1, 2}, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Radius(NewPoint(1, 1.000001), 1e-3, func(p Point, v int, _ float64) bool { return fn(p, v) })
+	}))
+
+	count := 0
+	x.Cell(GetHash(NewPoint(1, 1), 0), func(_ Point, _ int) bool {
+		count++
+		return false
+	})
+	assert.Equal(t, 1, count)
+
+	assert.False(t, x.Delete(nil, nil))
+	assert.False(t, x.Delete(NewPoint(1, 1.1), nil))
+	assert.False(t, x.Delete(NewPoint(1, 1), func(v int) bool { return v == 3 }))
+	assert.True(t, x.Delete(NewPoint(1, 1), func(v int) bool { return v == 2 }))
+	assert.True(t, x.Delete(NewPoint(90, 0), nil))
+	assert.Equal(t, 3, x.Len())
+	assert.Equal(t, []int{1, 3, 4}, getHashIndexResults(func(fn func(Point, int) bool) {
+		x.Cell(GetHash(NewPoint(1, 1), 0), fn)
+	}))
+}
+
+func TestHashIndex_Random(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+	x := NewHashIndex[int]()
+	points := make([]Point, 5000)
+
+	for i := range points {
+		points[i] = getRandomPoint(r)
+		x.Insert(points[i], i)
+	}
+
+	for i := 0; i < 200; i++ {
+		center := getRandomPoint(r)
+		radius := []float64{0.1, 10, 100, 1000, 10000}[i%5]
+		expected := []int{}
+
+		for j, p := range points {
+			if GetDistance(center, p) <= radius {
+				expected = append(expected, j)
+			}
+		}
+
+		assert.Equal(t, expected, getHashIndexResults(func(fn func(Point, int) bool) {
+			x.Radius(center, radius, func(p Point, v int, _ float64) bool { return fn(p, v) })
+		}), "%v %v", center, radius)
+
+		b := getRandomBoundary(r)
+		expected = []int{}
+
+		for j, p := range points {
+			if boundaryContains(b, p) {
+				expected = append(expected, j)
+			}
+		}
+
+		assert.Equal(t, expected, getHashIndexResults(func(fn func(Point, int) bool) {
+			x.Search(b, fn)
+		}), "%v", b)
+	}
+}
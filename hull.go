@@ -0,0 +1,107 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"sort"
+)
+
+// GetConvexHull returns the convex hull of the given geo-location points calculated on the sphere,
+// where the hull edges are great circle arcs rather than straight lines in latlng space.
+// The hull is returned as a closed ring of the given points in counter clockwise order, having the first point
+// repeated at the end, and points lying on the hull edges are left out.
+// Points spread around the antimeridian or a pole are handled as any other, while points that don't fit in a
+// single hemisphere have no hull, in which case ErrNotInHemisphere is returned.
+// Duplicate points are taken once, so a single distinct point makes the ring [p p], and if all the points are on
+// the same great circle arc, the ring degenerates to the two ends of that arc [a b a].
+func GetConvexHull(points []Point) ([]Point, error) {
+
+	type projected struct {
+		point Point
+		x, y  float64
+	}
+
+	vectors := make([]vector, 0, len(points))
+	valid := make([]Point, 0, len(points))
+	seen := make(map[[2]float64]bool)
+
+	for _, p := range points {
+		if p == nil || seen[[2]float64{p.Latitude(), p.Longitude()}] {
+			continue
+		}
+
+		seen[[2]float64{p.Latitude(), p.Longitude()}] = true
+		vectors = append(vectors, toVector(p))
+		valid = append(valid, p)
+	}
+
+	c, err := getEnclosingCap(vectors)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(valid) == 1 {
+		return []Point{valid[0], valid[0]}, nil
+	}
+
+	// Since all the points are in the hemisphere around the enclosing cap center, the gnomonic projection
+	// around it turns the spherical hull into a planar one.
+	g := newGnomonic(c.center)
+	list := make([]projected, len(valid))
+
+	for i, p := range valid {
+		x, y := g.project(toVector(p))
+		list[i] = projected{point: p, x: x, y: y}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].x == list[j].x {
+			return list[i].y < list[j].y
+		}
+		return list[i].x < list[j].x
+	})
+
+	turn := func(o, a, b projected) float64 {
+		return (a.x-o.x)*(b.y-o.y) - (a.y-o.y)*(b.x-o.x)
+	}
+
+	// Andrew's monotone chain, building the lower hull then the upper one.
+	hull := make([]projected, 0, 2*len(list))
+
+	for i := 0; i < len(list); i++ {
+		for len(hull) >= 2 && turn(hull[len(hull)-2], hull[len(hull)-1], list[i]) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, list[i])
+	}
+
+	for i, lower := len(list)-2, len(hull)+1; i >= 0; i-- {
+		for len(hull) >= lower && turn(hull[len(hull)-2], hull[len(hull)-1], list[i]) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, list[i])
+	}
+
+	ring := make([]Point, len(hull))
+
+	for i, p := range hull {
+		ring[i] = p.point
+	}
+
+	return ring, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetConvexHull(t *testing.T) {
+
+	_, err := GetConvexHull(nil)
+	assert.Equal(t, ErrEmptyPointSet, err)
+
+	_, err = GetConvexHull([]Point{NewPoint(0, 0), NewPoint(0, 120), NewPoint(0, -120)})
+	assert.Equal(t, ErrNotInHemisphere, err)
+
+	inner := NewPoint(0.5, 0.5)
+	edge := NewPoint(0, 0.5)
+	points := []Point{NewPoint(0, 0), inner, NewPoint(1, 1), edge, NewPoint(0, 1), NewPoint(1, 0)}
+
+	ring, err := GetConvexHull(points)
+	assert.NoError(t, err)
+	assert.Len(t, ring, 5)
+	assert.Equal(t, ring[0], ring[len(ring)-1])
+	assert.NotContains(t, ring, inner)
+	assert.NotContains(t, ring, edge)
+
+	// Counter clockwise ring, the signed area in the plane has to be positive.
+	area := 0.0
+	for i := 0; i < len(ring)-1; i++ {
+		area += ring[i].Longitude()*ring[i+1].Latitude() - ring[i+1].Longitude()*ring[i].Latitude()
+	}
+	assert.True(t, area > 0)
+}
+
+func TestGetConvexHull_Antimeridian(t *testing.T) {
+
+	inner := NewPoint(0, 180)
+	points := []Point{NewPoint(1, 179), NewPoint(-1, -179), inner, NewPoint(1, -179), NewPoint(-1, 179)}
+
+	ring, err := GetConvexHull(points)
+	assert.NoError(t, err)
+	assert.Len(t, ring, 5)
+	assert.NotContains(t, ring, inner)
+}
+
+func TestGetConvexHull_Pole(t *testing.T) {
+
+	points := []Point{NewPoint(90, 0)}
+
+	for lng := -180.0; lng < 180; lng += 30 {
+		points = append(points, NewPoint(80, lng))
+	}
+
+	ring, err := GetConvexHull(points)
+	assert.NoError(t, err)
+	assert.Len(t, ring, 13)
+	assert.NotContains(t, ring, points[0])
+
+	// The great circle arc between two points on the same parallel bulges towards the pole,
+	// leaving a point in the middle of that parallel inside the hull.
+	inner := NewPoint(60, 0)
+	points = []Point{NewPoint(60, -40), inner, NewPoint(60, 40), NewPoint(50, 0)}
+
+	ring, err = GetConvexHull(points)
+	assert.NoError(t, err)
+	assert.Len(t, ring, 4)
+	assert.NotContains(t, ring, inner)
+}
+
+func TestGetConvexHull_Random(t *testing.T) {
+
+	for i := 0; i < 100; i++ {
+		lat := rand.Float64()*180 - 90
+		lng := rand.Float64()*360 - 180
+		points := make([]Point, 100)
+
+		for j := range points {
+			points[j] = NewPoint(lat+rand.Float64()*20-10, lng+rand.Float64()*20-10)
+		}
+
+		ring, err := GetConvexHull(points)
+		assert.NoError(t, err)
+
+		// Every point has to be on the left of every hull edge.
+		for j := 0; j < len(ring)-1; j++ {
+			normal := toVector(ring[j]).cross(toVector(ring[j+1]))
+			for _, p := range points {
+				assert.True(t, normal.dot(toVector(p)) >= -1e-12)
+			}
+		}
+	}
+}
+
+func TestGetConvexHull_Degenerate(t *testing.T) {
+
+	p := NewPoint(10, 20)
+
+	ring, err := GetConvexHull([]Point{p})
+	assert.NoError(t, err)
+	assert.Equal(t, []Point{p, p}, ring)
+
+	ring, err = GetConvexHull([]Point{p, nil, NewPoint(10, 20), p})
+	assert.NoError(t, err)
+	assert.Equal(t, []Point{p, p}, ring)
+
+	// Duplicates don't show up twice in the ring.
+	square := []Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1), NewPoint(1, 0)}
+	ring, err = GetConvexHull(append(append([]Point{}, square...), square...))
+	assert.NoError(t, err)
+	assert.Len(t, ring, 5)
+	assert.Equal(t, ring[0], ring[len(ring)-1])
+
+	// Points on the same great circle arc make a ring of its two ends.
+	a, b := NewPoint(0, 0), NewPoint(0, 3)
+	ring, err = GetConvexHull([]Point{NewPoint(0, 1), b, a, NewPoint(0, 2), a})
+	assert.NoError(t, err)
+	assert.Len(t, ring, 3)
+	assert.Equal(t, ring[0], ring[2])
+	assert.ElementsMatch(t, []Point{a, b}, ring[:2])
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+)
+
+// getTangents returns the unit vectors tangent to the sphere at v pointing north and east.
+// Since north has no direction at the poles, it is taken as if going north along the Prime Meridian, so it's
+// along the 180 meridian at the north pole and along the Prime Meridian at the south pole.
+func getTangents(v vector) (vector, vector) {
+
+	north := direction(v, vector{0, 0, 1})
+
+	if north.norm() < epsilon {
+		north = vector{-math.Copysign(1, v.z), 0, 0}
+	}
+
+	return north, north.cross(v)
+}
+
+// getHeading returns the unit vector tangent to the sphere at v pointing at the given bearing in degrees,
+// clockwise from the north.
+func getHeading(v vector, bearing float64) vector {
+	north, east := getTangents(v)
+	return north.scale(math.Cos(bearing * radians)).add(east.scale(math.Sin(bearing * radians)))
+}
+
+// GetArcIntersection returns the geo-location point where the great circle arc from a1 to a2 intersects the
+// one from b1 to b2, if they intersect, where each arc is the shorter one between its ends.
+// Arcs touching at their ends intersect there, and arcs overlapping along the same great circle intersect at
+// the first end of the second arc lying on the first one, or else at the first end of the first arc lying on
+// the second one.
+func GetArcIntersection(a1, a2, b1, b2 Point) (Point, bool) {
+
+	if a1 == nil || a2 == nil || b1 == nil || b2 == nil {
+		return nil, false
+	}
+
+	a, b := toVector(a1), toVector(a2)
+	c, d := toVector(b1), toVector(b2)
+
+	onArc := func(v, from, to vector) bool {
+		closest, _ := closestOnArc(v, from, to)
+		return closest.angle(v) < epsilon
+	}
+
+	for _, end := range []struct {
+		point    Point
+		v        vector
+		from, to vector
+	}{{b1, c, a, b}, {b2, d, a, b}, {a1, a, c, d}, {a2, b, c, d}} {
+		if onArc(end.v, end.from, end.to) {
+			return end.point, true
+		}
+	}
+
+	if x, ok := arcsIntersection(a, b, c, d); ok {
+		return x.toPoint(), true
+	}
+
+	return nil, false
+}
+
+// GetPathIntersection returns the first geo-location point where the two paths intersect, each path starting at
+// the given point and following the great circle of the given bearing in degrees clockwise from the north, up
+// to half a great circle ahead.
+// It returns false if the paths are along the same great circle, or if they don't intersect ahead of both of
+// them. At the poles, the bearing is taken from the direction of going north along the Prime Meridian.
+func GetPathIntersection(p1 Point, bearing1 float64, p2 Point, bearing2 float64) (Point, bool) {
+
+	if p1 == nil || p2 == nil {
+		return nil, false
+	}
+
+	v1, v2 := toVector(p1), toVector(p2)
+	d1, d2 := getHeading(v1, bearing1), getHeading(v2, bearing2)
+
+	x := v1.cross(d1).cross(v2.cross(d2))
+
+	if x.norm() < epsilon {
+		return nil, false
+	}
+
+	x = x.normalize()
+
+	// Both great circles meet at x and its antipode, the one ahead of both paths is the intersection, the
+	// starting points themselves included.
+	for _, candidate := range []vector{x, x.scale(-1)} {
+		if d1.dot(candidate) >= -epsilon && d2.dot(candidate) >= -epsilon {
+			return candidate.toPoint(), true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTangents(t *testing.T) {
+
+	north, east := getTangents(toVector(NewPoint(0, 0)))
+	assert.InDelta(t, 1, north.z, DecimalPrecision)
+	assert.InDelta(t, 1, east.y, DecimalPrecision)
+
+	north, east = getTangents(toVector(NewPoint(90, 0)))
+	assert.InDelta(t, -1, north.x, DecimalPrecision)
+	assert.InDelta(t, 1, east.y, DecimalPrecision)
+
+	north, east = getTangents(toVector(NewPoint(-90, 0)))
+	assert.InDelta(t, 1, north.x, DecimalPrecision)
+	assert.InDelta(t, 1, east.y, DecimalPrecision)
+}
+
+func TestGetArcIntersection(t *testing.T) {
+
+	p, ok := GetArcIntersection(NewPoint(-1, 0), NewPoint(1, 0), NewPoint(0, -1), NewPoint(0, 1))
+	assert.True(t, ok)
+	assert.InDelta(t, 0, p.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 0, p.Longitude(), DecimalPrecision)
+
+	// Across the antimeridian.
+	p, ok = GetArcIntersection(NewPoint(-1, 179.5), NewPoint(1, -179.5), NewPoint(1, 179.5), NewPoint(-1, -179.5))
+	assert.True(t, ok)
+	assert.InDelta(t, 0, p.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 180, p.Longitude(), DecimalPrecision)
+
+	// The arcs bulge towards the pole, so they meet above the latitude of their ends.
+	p, ok = GetArcIntersection(NewPoint(60, -10), NewPoint(60, 10), NewPoint(59, 0), NewPoint(62, 0))
+	assert.True(t, ok)
+	assert.True(t, p.Latitude() > 60)
+
+	_, ok = GetArcIntersection(NewPoint(-1, 0), NewPoint(1, 0), NewPoint(0, 1), NewPoint(0, 2))
+	assert.False(t, ok)
+
+	// The great circles meet on the other side of earth, away from the arcs.
+	_, ok = GetArcIntersection(NewPoint(-1, 0), NewPoint(1, 0), NewPoint(0, 170), NewPoint(0, -170))
+	assert.False(t, ok)
+
+	// Touching at the ends.
+	p, ok = GetArcIntersection(NewPoint(0, 0), NewPoint(1, 1), NewPoint(1, 1), NewPoint(2, 0))
+	assert.True(t, ok)
+	assert.Equal(t, NewPoint(1, 1), p)
+
+	p, ok = GetArcIntersection(NewPoint(0, -1), NewPoint(0, 1), NewPoint(0, 0), NewPoint(1, 0))
+	assert.True(t, ok)
+	assert.Equal(t, NewPoint(0, 0), p)
+
+	// Overlapping.
+	p, ok = GetArcIntersection(NewPoint(0, 0), NewPoint(0, 2), NewPoint(0, 3), NewPoint(0, 1))
+	assert.True(t, ok)
+	assert.Equal(t, NewPoint(0, 1), p)
+
+	_, ok = GetArcIntersection(nil, NewPoint(0, 2), NewPoint(0, 3), NewPoint(0, 1))
+	assert.False(t, ok)
+}
+
+func TestGetPathIntersection(t *testing.T) {
+
+	p, ok := GetPathIntersection(NewPoint(0, -1), 90, NewPoint(-1, 0), 0)
+	assert.True(t, ok)
+	assert.InDelta(t, 0, p.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 0, p.Longitude(), DecimalPrecision)
+
+	// Going away from each other, the paths meet on the other side of earth.
+	p, ok = GetPathIntersection(NewPoint(0, -1), 270, NewPoint(-1, 0), 180)
+	assert.True(t, ok)
+	assert.InDelta(t, 0, p.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 180, p.Longitude(), DecimalPrecision)
+
+	// One of the paths is going away from the intersection ahead of the other.
+	_, ok = GetPathIntersection(NewPoint(0, -1), 90, NewPoint(-1, 0), 180)
+	assert.False(t, ok)
+
+	// Along the same great circle.
+	_, ok = GetPathIntersection(NewPoint(0, -1), 90, NewPoint(0, 5), 270)
+	assert.False(t, ok)
+
+	// Starting on the other path.
+	p, ok = GetPathIntersection(NewPoint(0, -1), 90, NewPoint(0, 0), 0)
+	assert.True(t, ok)
+	assert.InDelta(t, 0, p.Longitude(), DecimalPrecision)
+
+	// Meridians meet at the pole.
+	p, ok = GetPathIntersection(NewPoint(10, 20), 0, NewPoint(10, 100), 0)
+	assert.True(t, ok)
+	assert.Equal(t, NorthPoleLat, p.Latitude())
+
+	// Starting at the pole along the Prime Meridian.
+	p, ok = GetPathIntersection(NewPoint(90, 0), 180, NewPoint(0, -10), 90)
+	assert.True(t, ok)
+	assert.InDelta(t, 0, p.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 0, p.Longitude(), DecimalPrecision)
+
+	_, ok = GetPathIntersection(nil, 0, NewPoint(0, 0), 0)
+	assert.False(t, ok)
+}
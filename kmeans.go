@@ -0,0 +1,345 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// DefaultMaxIterations is the maximum number of iterations done by the center based clustering algorithms
+// when it's not specified in the options.
+const DefaultMaxIterations = 100
+
+// ErrInvalidClusterCount is returned when the number of clusters requested is less than one or greater than
+// the number of geo-location points given.
+var ErrInvalidClusterCount = errors.New("invalid cluster count")
+
+// CenterClusteringOptions carries the options of the center based clustering algorithms.
+type CenterClusteringOptions struct {
+	// Source is the source of the random numbers used to pick the initial centers, such as rand.NewSource(0),
+	// where a source of a given seed makes the results reproducible. When it's nil, a source seeded with the
+	// current time is used instead. A source is not safe for concurrent use, so it can't be shared by concurrent
+	// calls.
+	Source rand.Source
+	// MaxIterations is the maximum number of iterations, when it's zero DefaultMaxIterations is used instead.
+	MaxIterations int
+}
+
+// CenterClustering represents the result of one of the center based clustering algorithms.
+type CenterClustering interface {
+	Clustering
+	// Centers returns the center of each cluster, at the index used as the cluster label.
+	Centers() []Point
+	// Cost returns the sum of the distances in kilometers, as measured by GetDistance,
+	// between each point and the center of its cluster.
+	Cost() float64
+}
+
+type centerClustering struct {
+	*clustering
+	centers []Point
+	cost    float64
+}
+
+func (c *centerClustering) Centers() []Point {
+	if c != nil {
+		return c.centers
+	}
+	return nil
+}
+
+func (c *centerClustering) Cost() float64 {
+	if c != nil {
+		return c.cost
+	}
+	return 0
+}
+
+func (o CenterClusteringOptions) random() *rand.Rand {
+	if o.Source == nil {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return rand.New(o.Source)
+}
+
+func (o CenterClusteringOptions) iterations() int {
+	if o.MaxIterations <= 0 {
+		return DefaultMaxIterations
+	}
+	return o.MaxIterations
+}
+
+// getValidIndices returns the indices of the non nil points, after validating the requested cluster count.
+func getValidIndices(points []Point, k int) ([]int, error) {
+
+	valid := make([]int, 0, len(points))
+
+	for i, p := range points {
+		if p != nil {
+			valid = append(valid, i)
+		}
+	}
+
+	if k < 1 || k > len(valid) {
+		return nil, ErrInvalidClusterCount
+	}
+
+	return valid, nil
+}
+
+// getSeeds picks k of the given point indices as initial centers using the k-means++ seeding, where each next
+// center is picked with a probability proportional to the squared distance to the nearest center picked so far.
+func getSeeds(valid []int, k int, distance func(i, j int) float64, rnd *rand.Rand) []int {
+
+	seeds := []int{valid[rnd.Intn(len(valid))]}
+	nearest := make([]float64, len(valid))
+
+	for i := range nearest {
+		nearest[i] = math.Inf(1)
+	}
+
+	for len(seeds) < k {
+		last := seeds[len(seeds)-1]
+		total := 0.0
+
+		for i, idx := range valid {
+			d := distance(idx, last)
+			nearest[i] = math.Min(nearest[i], d*d)
+			total += nearest[i]
+		}
+
+		if total == 0 {
+			// All the remaining points are duplicates of the picked centers, any of them would do.
+			seeds = append(seeds, valid[rnd.Intn(len(valid))])
+			continue
+		}
+
+		target := rnd.Float64() * total
+		pick := valid[len(valid)-1]
+
+		for i, idx := range valid {
+			target -= nearest[i]
+			if target < 0 {
+				pick = idx
+				break
+			}
+		}
+
+		seeds = append(seeds, pick)
+	}
+
+	return seeds
+}
+
+// GetKMeansClusters groups the given geo-location points into k clusters using the spherical k-means algorithm,
+// where the points are treated as unit vectors and each center is the normalized mean of the vectors of its
+// cluster, so the clusters are not distorted around the antimeridian or the poles.
+// The initial centers are picked using the k-means++ seeding, and the iterations stop once the clusters don't
+// change, the maximum number of iterations is reached or the context is done, in which case the context error
+// is returned.
+// Nil points are labeled as noise.
+func GetKMeansClusters(ctx context.Context, points []Point, k int, options CenterClusteringOptions) (CenterClustering, error) {
+
+	valid, err := getValidIndices(points, k)
+
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make([]vector, len(points))
+
+	for _, i := range valid {
+		vectors[i] = toVector(points[i])
+	}
+
+	distance := func(i, j int) float64 {
+		return vectors[i].angle(vectors[j])
+	}
+
+	centers := make([]vector, k)
+
+	for i, seed := range getSeeds(valid, k, distance, options.random()) {
+		centers[i] = vectors[seed]
+	}
+
+	labels := make([]int, len(points))
+
+	for i := range labels {
+		labels[i] = Noise
+	}
+
+	// assign labels each point with its nearest center, reporting whether any label has changed.
+	assign := func() bool {
+		changed := false
+
+		for _, i := range valid {
+			best, bestDot := 0, math.Inf(-1)
+
+			for c, center := range centers {
+				if d := center.dot(vectors[i]); d > bestDot {
+					best, bestDot = c, d
+				}
+			}
+
+			if labels[i] != best {
+				labels[i] = best
+				changed = true
+			}
+		}
+
+		return changed
+	}
+
+	for iteration := 0; iteration < options.iterations(); iteration++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if !assign() {
+			break
+		}
+
+		sums := make([]vector, k)
+
+		for _, i := range valid {
+			sums[labels[i]] = sums[labels[i]].add(vectors[i])
+		}
+
+		for c, sum := range sums {
+			// An empty cluster, or one evenly spread around earth, keeps its previous center.
+			if sum.norm() >= epsilon {
+				centers[c] = sum.normalize()
+			}
+		}
+	}
+
+	// The last iteration may have moved the centers after assigning the points.
+	assign()
+
+	result := &centerClustering{clustering: newClustering(points, labels, k), centers: make([]Point, k)}
+
+	for c, center := range centers {
+		result.centers[c] = center.toPoint()
+	}
+
+	for _, i := range valid {
+		result.cost += GetDistance(points[i], result.centers[labels[i]])
+	}
+
+	return result, nil
+}
+
+// GetKMedoidsClusters groups the given geo-location points into k clusters using the k-medoids algorithm,
+// where each center is the cluster point having the least sum of distances to the rest of the cluster points,
+// as measured by GetDistance.
+// The initial centers are picked using the k-means++ seeding, and the iterations alternate between assigning
+// the points to their nearest center and picking the new centers, they stop once the centers don't change,
+// the maximum number of iterations is reached or the context is done, in which case the context error
+// is returned.
+// Nil points are labeled as noise.
+func GetKMedoidsClusters(ctx context.Context, points []Point, k int, options CenterClusteringOptions) (CenterClustering, error) {
+
+	valid, err := getValidIndices(points, k)
+
+	if err != nil {
+		return nil, err
+	}
+
+	distance := func(i, j int) float64 {
+		return GetDistance(points[i], points[j])
+	}
+
+	medoids := getSeeds(valid, k, distance, options.random())
+	labels := make([]int, len(points))
+	members := make([][]int, k)
+	cost := 0.0
+
+	for i := range labels {
+		labels[i] = Noise
+	}
+
+	// assign labels each point with its nearest medoid, collecting the cluster members and the total cost.
+	assign := func() {
+		members = make([][]int, k)
+		cost = 0
+
+		for _, i := range valid {
+			best, bestDistance := 0, math.Inf(1)
+
+			for c, medoid := range medoids {
+				if d := distance(i, medoid); d < bestDistance {
+					best, bestDistance = c, d
+				}
+			}
+
+			labels[i] = best
+			cost += bestDistance
+			members[best] = append(members[best], i)
+		}
+	}
+
+	assign()
+
+	for iteration := 0; iteration < options.iterations(); iteration++ {
+		changed := false
+
+		for c, list := range members {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			best, bestSum := medoids[c], math.Inf(1)
+
+			for _, candidate := range list {
+				sum := 0.0
+
+				for _, i := range list {
+					if sum += distance(candidate, i); sum >= bestSum {
+						break
+					}
+				}
+
+				if sum < bestSum {
+					best, bestSum = candidate, sum
+				}
+			}
+
+			if best != medoids[c] {
+				medoids[c] = best
+				changed = true
+			}
+		}
+
+		if !changed {
+			break
+		}
+
+		assign()
+	}
+
+	result := &centerClustering{clustering: newClustering(points, labels, k), centers: make([]Point, k), cost: cost}
+
+	for c, medoid := range medoids {
+		result.centers[c] = points[medoid]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getKMeansTestPoints() []Point {
+
+	rnd := rand.New(rand.NewSource(7))
+	points := []Point{}
+
+	// Three separated groups, one of them around the antimeridian and another one around the north pole.
+	for i := 0; i < 30; i++ {
+		points = append(points, NewPoint(30+rnd.Float64(), 31+rnd.Float64()))
+		points = append(points, NewPoint(-15+rnd.Float64(), 179.5+rnd.Float64()))
+		points = append(points, NewPoint(89+rnd.Float64(), rnd.Float64()*360-180))
+	}
+
+	return points
+}
+
+func assertKMeansGroups(t *testing.T, c CenterClustering) {
+
+	assert.Len(t, c.Centers(), 3)
+	assert.Len(t, c.Clusters(), 3)
+	assert.Empty(t, c.Noise())
+
+	for i := 0; i < 90; i++ {
+		assert.Equal(t, c.Labels()[i%3], c.Labels()[i])
+		assert.NotEqual(t, c.Labels()[(i+1)%3], c.Labels()[i])
+	}
+
+	total := 0.0
+	for i, p := range getKMeansTestPoints() {
+		total += GetDistance(p, c.Centers()[c.Labels()[i]])
+	}
+	assert.InDelta(t, total, c.Cost(), 0.001)
+}
+
+func TestGetKMeansClusters(t *testing.T) {
+
+	points := getKMeansTestPoints()
+
+	_, err := GetKMeansClusters(context.Background(), points, 0, CenterClusteringOptions{})
+	assert.Equal(t, ErrInvalidClusterCount, err)
+
+	_, err = GetKMeansClusters(context.Background(), []Point{nil, NewPoint(0, 0)}, 2, CenterClusteringOptions{})
+	assert.Equal(t, ErrInvalidClusterCount, err)
+
+	c, err := GetKMeansClusters(context.Background(), points, 3, CenterClusteringOptions{Source: rand.NewSource(42)})
+	assert.NoError(t, err)
+	assertKMeansGroups(t, c)
+
+	pole := c.Centers()[c.Labels()[2]]
+	assert.True(t, pole.Latitude() > 89)
+
+	antimeridian := c.Centers()[c.Labels()[1]]
+	assert.InDelta(t, 0, math.Remainder(antimeridian.Longitude()-180, TotalLongitude), 0.5)
+
+	again, err := GetKMeansClusters(context.Background(), points, 3, CenterClusteringOptions{Source: rand.NewSource(42)})
+	assert.NoError(t, err)
+	assert.Equal(t, c.Labels(), again.Labels())
+	assert.Equal(t, c.Centers(), again.Centers())
+
+	// A zero seed is as good as any other.
+	c, err = GetKMeansClusters(context.Background(), points, 3, CenterClusteringOptions{Source: rand.NewSource(0)})
+	assert.NoError(t, err)
+	again, err = GetKMeansClusters(context.Background(), points, 3, CenterClusteringOptions{Source: rand.NewSource(0)})
+	assert.NoError(t, err)
+	assert.Equal(t, c.Labels(), again.Labels())
+	assert.Equal(t, c.Centers(), again.Centers())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = GetKMeansClusters(ctx, points, 3, CenterClusteringOptions{})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestGetKMedoidsClusters(t *testing.T) {
+
+	points := getKMeansTestPoints()
+
+	_, err := GetKMedoidsClusters(context.Background(), points, 91, CenterClusteringOptions{})
+	assert.Equal(t, ErrInvalidClusterCount, err)
+
+	c, err := GetKMedoidsClusters(context.Background(), points, 3, CenterClusteringOptions{Source: rand.NewSource(42)})
+	assert.NoError(t, err)
+	assertKMeansGroups(t, c)
+
+	for label, center := range c.Centers() {
+		assert.Contains(t, c.Clusters()[label].Indices(), indexOfPoint(points, center))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = GetKMedoidsClusters(ctx, points, 3, CenterClusteringOptions{MaxIterations: 5})
+	assert.Equal(t, context.Canceled, err)
+
+	var r *centerClustering
+	var cr CenterClustering = r
+
+	assert.Nil(t, cr.Centers())
+	assert.InDelta(t, 0, cr.Cost(), 0)
+}
+
+func indexOfPoint(points []Point, p Point) int {
+	for i, q := range points {
+		if q == p {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,649 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KMLNamespace is the XML namespace of KML 2.2 documents.
+const KMLNamespace = "http://www.opengis.net/kml/2.2"
+
+var (
+	// ErrInvalidKML is returned when reading a document that is not a KML document.
+	ErrInvalidKML = errors.New("invalid kml document")
+	// ErrInvalidKMZ is returned when reading an archive that has no KML document.
+	ErrInvalidKMZ = errors.New("invalid kmz archive")
+)
+
+// KML is a KML document, holding placemarks.
+// The altitudes of the coordinates are not kept, while the styles are kept as raw XML, so that writing the
+// document back keeps them.
+type KML struct {
+	// Name is the name of the document.
+	Name string
+	// Styles is the raw XML of the shared Style and StyleMap elements of the document.
+	Styles []byte
+	// Placemarks are the placemarks found anywhere in the document, including the folders.
+	Placemarks []KMLPlacemark
+}
+
+// KMLPlacemark is a feature of a KML document with its geometry, where the geometries of a MultiGeometry are
+// all listed together.
+type KMLPlacemark struct {
+	// ID is the identifier of the placemark.
+	ID string
+	// Name is the name of the placemark.
+	Name string
+	// Description is the description of the placemark.
+	Description string
+	// StyleURL is the reference to a shared style of the placemark.
+	StyleURL string
+	// Style is the raw XML of the inline Style and StyleMap elements of the placemark.
+	Style []byte
+	// Points are the points of the placemark geometry.
+	Points []Point
+	// Lines are the lines of the placemark geometry.
+	Lines []LineString
+	// Polygons are the polygons of the placemark geometry.
+	Polygons []Polygon
+	// Boundary is the latlng box of the placemark region, if it has one.
+	Boundary Boundary
+	// ExtendedData holds the custom data of the placemark by name.
+	ExtendedData map[string]string
+}
+
+// kmlRaw is an element kept as raw XML.
+type kmlRaw struct {
+	XMLName    xml.Name
+	Attributes []xml.Attr `xml:",any,attr"`
+	Content    []byte     `xml:",innerxml"`
+}
+
+type kmlCoordinates struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlBoundary struct {
+	Ring kmlCoordinates `xml:"LinearRing"`
+}
+
+type kmlPolygon struct {
+	Outer kmlBoundary   `xml:"outerBoundaryIs"`
+	Inner []kmlBoundary `xml:"innerBoundaryIs"`
+}
+
+type kmlMultiGeometry struct {
+	Points   []kmlCoordinates   `xml:"Point"`
+	Lines    []kmlCoordinates   `xml:"LineString"`
+	Polygons []kmlPolygon       `xml:"Polygon"`
+	Multi    []kmlMultiGeometry `xml:"MultiGeometry"`
+}
+
+type kmlBox struct {
+	North float64 `xml:"north"`
+	South float64 `xml:"south"`
+	East  float64 `xml:"east"`
+	West  float64 `xml:"west"`
+}
+
+type kmlRegion struct {
+	Box kmlBox `xml:"LatLonAltBox"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlSimpleData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type kmlSchemaData struct {
+	Data []kmlSimpleData `xml:"SimpleData"`
+}
+
+type kmlExtendedData struct {
+	Data       []kmlData       `xml:"Data"`
+	SchemaData []kmlSchemaData `xml:"SchemaData"`
+}
+
+type kmlPlacemark struct {
+	XMLName       xml.Name          `xml:"Placemark"`
+	ID            string            `xml:"id,attr,omitempty"`
+	Name          string            `xml:"name,omitempty"`
+	Description   string            `xml:"description,omitempty"`
+	StyleURL      string            `xml:"styleUrl,omitempty"`
+	Styles        []kmlRaw          `xml:"Style"`
+	StyleMaps     []kmlRaw          `xml:"StyleMap"`
+	Region        *kmlRegion        `xml:"Region"`
+	ExtendedData  *kmlExtendedData  `xml:"ExtendedData"`
+	Point         *kmlCoordinates   `xml:"Point"`
+	LineString    *kmlCoordinates   `xml:"LineString"`
+	Polygon       *kmlPolygon       `xml:"Polygon"`
+	MultiGeometry *kmlMultiGeometry `xml:"MultiGeometry"`
+}
+
+type kmlDocument struct {
+	Name       string         `xml:"name,omitempty"`
+	Styles     []kmlRaw       `xml:"Style"`
+	StyleMaps  []kmlRaw       `xml:"StyleMap"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlRoot struct {
+	XMLName  xml.Name    `xml:"kml"`
+	XMLNS    string      `xml:"xmlns,attr"`
+	Document kmlDocument `xml:"Document"`
+}
+
+// parseKMLCoordinates returns the points of the given KML coordinates, which are longitude, latitude and the
+// optional altitude separated by commas, each tuple separated by white spaces.
+// White spaces around the commas are tolerated, as some writers put a space after each comma.
+func parseKMLCoordinates(coordinates string) ([]Point, error) {
+
+	tuples := []string{}
+
+	for _, field := range strings.Fields(coordinates) {
+		if n := len(tuples); n > 0 && (strings.HasSuffix(tuples[n-1], ",") || strings.HasPrefix(field, ",")) {
+			tuples[n-1] += field
+		} else {
+			tuples = append(tuples, field)
+		}
+	}
+
+	points := []Point{}
+
+	for _, tuple := range tuples {
+		values := strings.Split(tuple, ",")
+
+		if len(values) < 2 || len(values) > 3 {
+			return nil, ErrInvalidKML
+		}
+
+		lng, err := strconv.ParseFloat(strings.TrimSpace(values[0]), 64)
+
+		if err != nil {
+			return nil, err
+		}
+
+		lat, err := strconv.ParseFloat(strings.TrimSpace(values[1]), 64)
+
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, NewPoint(lat, lng))
+	}
+
+	return points, nil
+}
+
+// formatKMLCoordinates returns the KML coordinates of the given points, skipping the nil ones.
+func formatKMLCoordinates(points []Point) kmlCoordinates {
+
+	tuples := make([]string, 0, len(points))
+
+	for _, p := range points {
+		if p != nil {
+			lng := strconv.FormatFloat(p.Longitude(), 'f', -1, 64)
+			lat := strconv.FormatFloat(p.Latitude(), 'f', -1, 64)
+			tuples = append(tuples, lng+","+lat)
+		}
+	}
+
+	return kmlCoordinates{Coordinates: strings.Join(tuples, " ")}
+}
+
+// formatKMLRing returns the KML coordinates of the given ring, skipping the nil points and repeating the first
+// point at the end if needed, as KML requires the linear rings to be closed.
+func formatKMLRing(ring []Point) kmlCoordinates {
+	return formatKMLCoordinates(closeRing(getNonNilPoints(ring)))
+}
+
+// getRawXML returns the given raw elements as XML.
+func getRawXML(elements []kmlRaw) ([]byte, error) {
+
+	var b bytes.Buffer
+
+	for _, e := range elements {
+		// The namespace is the one of the document, which doesn't have to be repeated.
+		e.XMLName.Space = ""
+
+		if err := xml.NewEncoder(&b).Encode(e); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.Bytes(), nil
+}
+
+// parseRawXML returns the Style and StyleMap elements of the given raw XML, skipping any other content.
+func parseRawXML(content []byte) ([]kmlRaw, []kmlRaw, error) {
+
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	styles, styleMaps := []kmlRaw{}, []kmlRaw{}
+
+	for {
+		token, err := decoder.Token()
+
+		if err == io.EOF {
+			return styles, styleMaps, nil
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if t, ok := token.(xml.StartElement); ok {
+			var e kmlRaw
+
+			if err := decoder.DecodeElement(&e, &t); err != nil {
+				return nil, nil, err
+			}
+
+			switch t.Name.Local {
+			case "Style":
+				styles = append(styles, e)
+			case "StyleMap":
+				styleMaps = append(styleMaps, e)
+			}
+		}
+	}
+}
+
+// addTo adds the geometries of the given multi-geometry to the placemark.
+func (m *kmlMultiGeometry) addTo(p *KMLPlacemark) error {
+
+	for _, c := range m.Points {
+		points, err := parseKMLCoordinates(c.Coordinates)
+
+		if err != nil {
+			return err
+		}
+
+		p.Points = append(p.Points, points...)
+	}
+
+	for _, c := range m.Lines {
+		points, err := parseKMLCoordinates(c.Coordinates)
+
+		if err != nil {
+			return err
+		}
+
+		p.Lines = append(p.Lines, NewLineString(points))
+	}
+
+	for _, polygon := range m.Polygons {
+		shell, err := parseKMLCoordinates(polygon.Outer.Ring.Coordinates)
+
+		if err != nil {
+			return err
+		}
+
+		holes := [][]Point{}
+
+		for _, inner := range polygon.Inner {
+			hole, err := parseKMLCoordinates(inner.Ring.Coordinates)
+
+			if err != nil {
+				return err
+			}
+
+			holes = append(holes, hole)
+		}
+
+		p.Polygons = append(p.Polygons, NewPolygon(shell, holes...))
+	}
+
+	for i := range m.Multi {
+		if err := m.Multi[i].addTo(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (k kmlPlacemark) toPlacemark() (KMLPlacemark, error) {
+
+	p := KMLPlacemark{ID: k.ID, Name: k.Name, Description: k.Description, StyleURL: k.StyleURL}
+
+	style, err := getRawXML(append(k.Styles, k.StyleMaps...))
+
+	if err != nil {
+		return p, err
+	}
+
+	if len(style) > 0 {
+		p.Style = style
+	}
+
+	if k.Region != nil {
+		b := k.Region.Box
+		p.Boundary = NewBoundary(NewPoint(b.South, b.West), NewPoint(b.North, b.East))
+	}
+
+	if k.ExtendedData != nil {
+		p.ExtendedData = make(map[string]string)
+
+		for _, d := range k.ExtendedData.Data {
+			p.ExtendedData[d.Name] = d.Value
+		}
+
+		for _, s := range k.ExtendedData.SchemaData {
+			for _, d := range s.Data {
+				p.ExtendedData[d.Name] = d.Value
+			}
+		}
+	}
+
+	// A single geometry is read as a multi-geometry of one.
+	m := kmlMultiGeometry{}
+
+	switch {
+	case k.Point != nil:
+		m.Points = append(m.Points, *k.Point)
+	case k.LineString != nil:
+		m.Lines = append(m.Lines, *k.LineString)
+	case k.Polygon != nil:
+		m.Polygons = append(m.Polygons, *k.Polygon)
+	case k.MultiGeometry != nil:
+		m = *k.MultiGeometry
+	}
+
+	return p, m.addTo(&p)
+}
+
+func newKMLPlacemark(p KMLPlacemark) (kmlPlacemark, error) {
+
+	k := kmlPlacemark{ID: p.ID, Name: p.Name, Description: p.Description, StyleURL: p.StyleURL}
+
+	var err error
+
+	if k.Styles, k.StyleMaps, err = parseRawXML(p.Style); err != nil {
+		return k, err
+	}
+
+	if p.Boundary != nil {
+		lower, upper := p.Boundary.Lower(), p.Boundary.Upper()
+		k.Region = &kmlRegion{Box: kmlBox{
+			North: upper.Latitude(),
+			South: lower.Latitude(),
+			East:  upper.Longitude(),
+			West:  lower.Longitude(),
+		}}
+	}
+
+	if len(p.ExtendedData) > 0 {
+		names := make([]string, 0, len(p.ExtendedData))
+
+		for name := range p.ExtendedData {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+		k.ExtendedData = &kmlExtendedData{}
+
+		for _, name := range names {
+			k.ExtendedData.Data = append(k.ExtendedData.Data, kmlData{Name: name, Value: p.ExtendedData[name]})
+		}
+	}
+
+	m := &kmlMultiGeometry{}
+
+	for _, point := range p.Points {
+		if point != nil {
+			m.Points = append(m.Points, formatKMLCoordinates([]Point{point}))
+		}
+	}
+
+	for _, line := range p.Lines {
+		if line != nil {
+			m.Lines = append(m.Lines, formatKMLCoordinates(line.Points()))
+		}
+	}
+
+	for _, polygon := range p.Polygons {
+		if polygon == nil {
+			continue
+		}
+
+		kp := kmlPolygon{Outer: kmlBoundary{Ring: formatKMLRing(polygon.Shell())}}
+
+		for _, hole := range polygon.Holes() {
+			kp.Inner = append(kp.Inner, kmlBoundary{Ring: formatKMLRing(hole)})
+		}
+
+		m.Polygons = append(m.Polygons, kp)
+	}
+
+	// A single geometry is written as it is, otherwise the geometries are written as a multi-geometry.
+	switch len(m.Points) + len(m.Lines) + len(m.Polygons) {
+	case 0:
+	case 1:
+		switch {
+		case len(m.Points) == 1:
+			k.Point = &m.Points[0]
+		case len(m.Lines) == 1:
+			k.LineString = &m.Lines[0]
+		default:
+			k.Polygon = &m.Polygons[0]
+		}
+	default:
+		k.MultiGeometry = m
+	}
+
+	return k, nil
+}
+
+// ReadKML reads a KML document from the given reader, collecting its placemarks from all its folders.
+func ReadKML(r io.Reader) (*KML, error) {
+
+	decoder := xml.NewDecoder(r)
+	k := &KML{}
+	parents := []string{}
+	styles := []kmlRaw{}
+
+	for {
+		token, err := decoder.Token()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(parents) == 0 && t.Name.Local != "kml" {
+				return nil, ErrInvalidKML
+			}
+
+			switch {
+			case t.Name.Local == "Placemark":
+				var placemark kmlPlacemark
+
+				if err := decoder.DecodeElement(&placemark, &t); err != nil {
+					return nil, err
+				}
+
+				p, err := placemark.toPlacemark()
+
+				if err != nil {
+					return nil, err
+				}
+
+				k.Placemarks = append(k.Placemarks, p)
+				continue
+			case t.Name.Local == "Style" || t.Name.Local == "StyleMap":
+				var style kmlRaw
+
+				if err := decoder.DecodeElement(&style, &t); err != nil {
+					return nil, err
+				}
+
+				styles = append(styles, style)
+				continue
+			case t.Name.Local == "name" && len(parents) == 2 && parents[1] == "Document" && k.Name == "":
+				if err := decoder.DecodeElement(&k.Name, &t); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+
+			parents = append(parents, t.Name.Local)
+		case xml.EndElement:
+			parents = parents[:len(parents)-1]
+		}
+	}
+
+	if len(parents) != 0 {
+		return nil, ErrInvalidKML
+	}
+
+	var err error
+
+	if k.Styles, err = getRawXML(styles); err != nil {
+		return nil, err
+	}
+
+	if len(k.Styles) == 0 {
+		k.Styles = nil
+	}
+
+	return k, nil
+}
+
+// WriteKML writes the given KML document to the given writer, with all its placemarks in a single Document
+// element, where a placemark having more than a single geometry is written as a MultiGeometry.
+// A nil document is written as an empty one.
+func WriteKML(w io.Writer, k *KML) error {
+
+	if k == nil {
+		k = &KML{}
+	}
+
+	root := kmlRoot{XMLNS: KMLNamespace, Document: kmlDocument{Name: k.Name}}
+
+	var err error
+
+	if root.Document.Styles, root.Document.StyleMaps, err = parseRawXML(k.Styles); err != nil {
+		return err
+	}
+
+	for _, p := range k.Placemarks {
+		placemark, err := newKMLPlacemark(p)
+
+		if err != nil {
+			return err
+		}
+
+		root.Document.Placemarks = append(root.Document.Placemarks, placemark)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+
+	return err
+}
+
+// ReadKMZ reads the KML document of the KMZ archive in the given reader having the given size, which is the
+// doc.kml file of the archive, or its first KML file if it has no doc.kml file.
+func ReadKMZ(r io.ReaderAt, size int64) (*KML, error) {
+
+	archive, err := zip.NewReader(r, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var document *zip.File
+
+	for _, f := range archive.File {
+		if f.Name == "doc.kml" {
+			document = f
+			break
+		}
+
+		if document == nil && strings.EqualFold(path.Ext(f.Name), ".kml") {
+			document = f
+		}
+	}
+
+	if document == nil {
+		return nil, ErrInvalidKMZ
+	}
+
+	rc, err := document.Open()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ReadKML(bytes.NewReader(content))
+}
+
+// WriteKMZ writes the given KML document to the given writer as a KMZ archive, holding the document as doc.kml.
+// A nil document is written as an empty one.
+func WriteKMZ(w io.Writer, k *KML) error {
+
+	archive := zip.NewWriter(w)
+	f, err := archive.Create("doc.kml")
+
+	if err != nil {
+		return err
+	}
+
+	if err := WriteKML(f, k); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testKML = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+  <Document>
+    <name>Zones</name>
+    <Style id="red"><PolyStyle><color>7f0000ff</color></PolyStyle></Style>
+    <Folder>
+      <name>Depots</name>
+      <Placemark id="depot">
+        <name>Depot</name>
+        <styleUrl>#red</styleUrl>
+        <ExtendedData>
+          <Data name="capacity"><value>12</value></Data>
+          <SchemaData schemaUrl="#s"><SimpleData name="code">D1</SimpleData></SchemaData>
+        </ExtendedData>
+        <Point><coordinates>-122.25,45.5,10</coordinates></Point>
+      </Placemark>
+    </Folder>
+    <Placemark>
+      <name>Zone</name>
+      <Style><LineStyle><width>2</width></LineStyle></Style>
+      <Region><LatLonAltBox><north>1</north><south>-1</south><east>-179</east><west>179</west></LatLonAltBox></Region>
+      <Polygon>
+        <outerBoundaryIs><LinearRing><coordinates>
+          179,-1 -179,-1 -179,1 179,1 179,-1
+        </coordinates></LinearRing></outerBoundaryIs>
+        <innerBoundaryIs><LinearRing><coordinates>179.5,-0.5 -179.5,-0.5 -179.5,0.5 179.5,0.5 179.5,-0.5</coordinates></LinearRing></innerBoundaryIs>
+      </Polygon>
+    </Placemark>
+    <Placemark>
+      <name>Route</name>
+      <MultiGeometry>
+        <LineString><coordinates>0,0 1,1</coordinates></LineString>
+        <MultiGeometry><Point><coordinates>2,2</coordinates></Point></MultiGeometry>
+      </MultiGeometry>
+    </Placemark>
+  </Document>
+</kml>`
+
+func TestReadKML(t *testing.T) {
+
+	k, err := ReadKML(strings.NewReader(testKML))
+	assert.Nil(t, err)
+	assert.Equal(t, "Zones", k.Name)
+	assert.Contains(t, string(k.Styles), `<Style id="red">`)
+	assert.Len(t, k.Placemarks, 3)
+
+	depot := k.Placemarks[0]
+	assert.Equal(t, "depot", depot.ID)
+	assert.Equal(t, "Depot", depot.Name)
+	assert.Equal(t, "#red", depot.StyleURL)
+	assert.Equal(t, []Point{NewPoint(45.5, -122.25)}, depot.Points)
+	assert.Equal(t, map[string]string{"capacity": "12", "code": "D1"}, depot.ExtendedData)
+
+	zone := k.Placemarks[1]
+	assert.Contains(t, string(zone.Style), "<width>2</width>")
+	assert.Len(t, zone.Polygons, 1)
+	assert.Len(t, zone.Polygons[0].Holes(), 1)
+	assert.True(t, zone.Polygons[0].Contains(NewPoint(0.8, 180)))
+	assert.False(t, zone.Polygons[0].Contains(NewPoint(0.1, 180)))
+	assert.Equal(t, NewPoint(-1, 179), zone.Boundary.Lower())
+	assert.Equal(t, NewPoint(1, -179), zone.Boundary.Upper())
+
+	route := k.Placemarks[2]
+	assert.Len(t, route.Lines, 1)
+	assert.Equal(t, []Point{NewPoint(0, 0), NewPoint(1, 1)}, route.Lines[0].Points())
+	assert.Equal(t, []Point{NewPoint(2, 2)}, route.Points)
+	assert.Nil(t, route.ExtendedData)
+
+	_, err = ReadKML(strings.NewReader("<gpx></gpx>"))
+	assert.Equal(t, ErrInvalidKML, err)
+
+	_, err = ReadKML(strings.NewReader("<kml><Placemark><Point><coordinates>1</coordinates></Point></Placemark></kml>"))
+	assert.Equal(t, ErrInvalidKML, err)
+
+	_, err = ReadKML(strings.NewReader("<kml><Placemark><Point><coordinates>a,1</coordinates></Point></Placemark></kml>"))
+	assert.NotNil(t, err)
+
+	_, err = ReadKML(strings.NewReader("<kml><Document>"))
+	assert.NotNil(t, err)
+}
+
+func TestReadKML_Spaces(t *testing.T) {
+
+	k, err := ReadKML(strings.NewReader("<kml><Placemark><LineString><coordinates>\n  1, 2, 3\n  4 ,5\t6,7 ,8\n</coordinates></LineString></Placemark></kml>"))
+	assert.Nil(t, err)
+	assert.Equal(t, []Point{NewPoint(2, 1), NewPoint(5, 4), NewPoint(7, 6)}, k.Placemarks[0].Lines[0].Points())
+
+	_, err = ReadKML(strings.NewReader("<kml><Placemark><Point><coordinates>1, 2, 3, 4</coordinates></Point></Placemark></kml>"))
+	assert.Equal(t, ErrInvalidKML, err)
+}
+
+func TestWriteKML(t *testing.T) {
+
+	k, err := ReadKML(strings.NewReader(testKML))
+	assert.Nil(t, err)
+
+	var b bytes.Buffer
+	assert.Nil(t, WriteKML(&b, k))
+
+	out := b.String()
+	assert.Contains(t, out, `<kml xmlns="http://www.opengis.net/kml/2.2">`)
+	assert.Contains(t, out, `<Style id="red"><PolyStyle><color>7f0000ff</color></PolyStyle></Style>`)
+	assert.Contains(t, out, "<coordinates>-122.25,45.5</coordinates>")
+	assert.Contains(t, out, "<MultiGeometry>")
+
+	again, err := ReadKML(strings.NewReader(out))
+	assert.Nil(t, err)
+	assert.Equal(t, k.Name, again.Name)
+	assert.Equal(t, k.Styles, again.Styles)
+	assert.Len(t, again.Placemarks, 3)
+
+	for i, p := range k.Placemarks {
+		q := again.Placemarks[i]
+		assert.Equal(t, p.ID, q.ID)
+		assert.Equal(t, p.Name, q.Name)
+		assert.Equal(t, p.StyleURL, q.StyleURL)
+		assert.Equal(t, p.Style, q.Style)
+		assert.Equal(t, p.Points, q.Points)
+		assert.Equal(t, p.Boundary, q.Boundary)
+		assert.Equal(t, p.ExtendedData, q.ExtendedData)
+		assert.Equal(t, len(p.Lines), len(q.Lines))
+		assert.Equal(t, len(p.Polygons), len(q.Polygons))
+
+		for j := range p.Polygons {
+			assert.Equal(t, p.Polygons[j].Shell(), q.Polygons[j].Shell())
+			assert.Equal(t, p.Polygons[j].Holes(), q.Polygons[j].Holes())
+		}
+	}
+
+	assert.NotNil(t, WriteKML(&b, &KML{Styles: []byte("<Style>")}))
+}
+
+func TestWriteKML_Rings(t *testing.T) {
+
+	// The rings are written closed, even if the polygon rings are not.
+	shell := []Point{NewPoint(0, 0), NewPoint(0, 2), NewPoint(2, 2), nil, NewPoint(2, 0)}
+	hole := []Point{NewPoint(0.5, 0.5), NewPoint(1, 0.5), NewPoint(1, 1)}
+
+	var b bytes.Buffer
+	assert.Nil(t, WriteKML(&b, &KML{Placemarks: []KMLPlacemark{{Polygons: []Polygon{NewPolygon(shell, hole)}}}}))
+
+	out := b.String()
+	assert.Contains(t, out, "<coordinates>0,0 2,0 2,2 0,2 0,0</coordinates>")
+	assert.Contains(t, out, "<coordinates>0.5,0.5 0.5,1 1,1 0.5,0.5</coordinates>")
+
+	k, err := ReadKML(strings.NewReader(out))
+	assert.Nil(t, err)
+	assert.Len(t, k.Placemarks[0].Polygons[0].Shell(), 5)
+	assert.Len(t, k.Placemarks[0].Polygons[0].Holes()[0], 4)
+}
+
+func TestKMZ(t *testing.T) {
+
+	k, err := ReadKML(strings.NewReader(testKML))
+	assert.Nil(t, err)
+
+	var b bytes.Buffer
+	assert.Nil(t, WriteKMZ(&b, k))
+
+	again, err := ReadKMZ(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	assert.Nil(t, err)
+	assert.Equal(t, k.Name, again.Name)
+	assert.Len(t, again.Placemarks, 3)
+
+	// An archive with another KML file name.
+	b.Reset()
+	archive := zip.NewWriter(&b)
+	f, _ := archive.Create("files/zones.KML")
+	_, _ = f.Write([]byte(testKML))
+	assert.Nil(t, archive.Close())
+
+	again, err = ReadKMZ(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	assert.Nil(t, err)
+	assert.Len(t, again.Placemarks, 3)
+
+	// An archive with no KML file.
+	b.Reset()
+	archive = zip.NewWriter(&b)
+	_, _ = archive.Create("image.png")
+	assert.Nil(t, archive.Close())
+
+	_, err = ReadKMZ(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	assert.Equal(t, ErrInvalidKMZ, err)
+
+	_, err = ReadKMZ(bytes.NewReader([]byte("nope")), 4)
+	assert.NotNil(t, err)
+}
+
+func TestWriteKML_Nil(t *testing.T) {
+
+	var b bytes.Buffer
+	assert.Nil(t, WriteKML(&b, nil))
+
+	k, err := ReadKML(bytes.NewReader(b.Bytes()))
+	assert.Nil(t, err)
+	assert.Empty(t, k.Placemarks)
+
+	b.Reset()
+	assert.Nil(t, WriteKMZ(&b, nil))
+
+	k, err = ReadKMZ(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	assert.Nil(t, err)
+	assert.Empty(t, k.Placemarks)
+}
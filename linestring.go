@@ -0,0 +1,209 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// LineString is a line going through a sequence of geo-location points, where each two consecutive points are
+// connected by the shortest great circle arc between them.
+// All the distances are great circle distances measured in kilometers.
+type LineString interface {
+	// Points returns the points the line is going through.
+	Points() []Point
+	// Length returns the length of the line.
+	Length() float64
+	// PointAt returns the point on the line at the given distance from its start, the distance is capped
+	// between zero and the line length.
+	PointAt(distanceInKM float64) Point
+	// PointAtFraction returns the point on the line at the given fraction of its length, the fraction is
+	// capped between zero and one.
+	PointAtFraction(fraction float64) Point
+	// Resample returns a new line with points placed along this line at the given spacing, starting at its
+	// first point and ending with its last point even if it's closer than the spacing.
+	Resample(spacingInKM float64) LineString
+	// Reverse returns a new line going through the same points in the reverse order.
+	Reverse() LineString
+	// Boundary returns the smallest boundary containing the line.
+	Boundary() Boundary
+	// Substring returns the part of the line between the two given distances from its start, the distances are
+	// capped between zero and the line length, and the returned line is reversed if from is greater than to.
+	Substring(fromInKM, toInKM float64) LineString
+}
+
+type lineString struct {
+	points  []Point
+	vectors []vector
+	// distances holds the great circle distance from the start of the line to each point in radians.
+	distances []float64
+}
+
+func (l *lineString) String() string {
+	return fmt.Sprintf("%v", l.Points())
+}
+
+func (l *lineString) Points() []Point {
+	if l != nil {
+		return l.points
+	}
+	return nil
+}
+
+func (l *lineString) Length() float64 {
+	if l == nil || len(l.distances) == 0 {
+		return 0
+	}
+	return angleToKM(l.distances[len(l.distances)-1])
+}
+
+// locate returns the index of the arc and the fraction along it at the given distance in radians from the start.
+func (l *lineString) locate(distance float64) (int, float64) {
+
+	last := len(l.distances) - 1
+
+	if distance <= 0 || last == 0 {
+		return 0, 0
+	}
+
+	if distance >= l.distances[last] {
+		return last - 1, 1
+	}
+
+	// The first point further than the distance ends the arc containing it.
+	i := sort.SearchFloat64s(l.distances, distance)
+
+	if l.distances[i] == distance {
+		return i - 1, 1
+	}
+
+	return i - 1, (distance - l.distances[i-1]) / (l.distances[i] - l.distances[i-1])
+}
+
+func (l *lineString) pointAt(distance float64) Point {
+
+	i, f := l.locate(distance)
+
+	// Returning the line points themselves whenever possible, instead of rebuilding them out of their vectors.
+	if f == 0 {
+		return l.points[i]
+	}
+
+	if f == 1 {
+		return l.points[i+1]
+	}
+
+	return interpolate(l.vectors[i], l.vectors[i+1], f).toPoint()
+}
+
+func (l *lineString) PointAt(distanceInKM float64) Point {
+	if l == nil || len(l.points) == 0 {
+		return nil
+	}
+	return l.pointAt(kmToAngle(distanceInKM))
+}
+
+func (l *lineString) PointAtFraction(fraction float64) Point {
+	if l == nil || len(l.points) == 0 {
+		return nil
+	}
+	return l.pointAt(math.Max(0, math.Min(1, fraction)) * l.distances[len(l.distances)-1])
+}
+
+func (l *lineString) Resample(spacingInKM float64) LineString {
+
+	if l == nil || len(l.points) < 2 || spacingInKM <= 0 {
+		return NewLineString(l.Points())
+	}
+
+	spacing := kmToAngle(spacingInKM)
+	length := l.distances[len(l.distances)-1]
+	points := []Point{}
+
+	for i := 0; float64(i)*spacing < length; i++ {
+		points = append(points, l.pointAt(float64(i)*spacing))
+	}
+
+	return NewLineString(append(points, l.points[len(l.points)-1]))
+}
+
+func (l *lineString) Reverse() LineString {
+
+	points := make([]Point, len(l.Points()))
+
+	for i, p := range l.Points() {
+		points[len(points)-1-i] = p
+	}
+
+	return NewLineString(points)
+}
+
+func (l *lineString) Boundary() Boundary {
+	return getLineBoundary(l.Points())
+}
+
+func (l *lineString) Substring(fromInKM, toInKM float64) LineString {
+
+	if l == nil || len(l.points) == 0 {
+		return NewLineString(nil)
+	}
+
+	if fromInKM > toInKM {
+		return l.Substring(toInKM, fromInKM).Reverse()
+	}
+
+	length := l.distances[len(l.distances)-1]
+	from := math.Max(0, math.Min(length, kmToAngle(fromInKM)))
+	to := math.Max(0, math.Min(length, kmToAngle(toInKM)))
+
+	points := []Point{l.pointAt(from)}
+
+	for i, d := range l.distances {
+		if d > from && d < to {
+			points = append(points, l.points[i])
+		}
+	}
+
+	return NewLineString(append(points, l.pointAt(to)))
+}
+
+// NewLineString creates a new line going through the given geo-location points, nil points are left out.
+func NewLineString(points []Point) LineString {
+
+	l := &lineString{}
+
+	for _, p := range points {
+		if p == nil {
+			continue
+		}
+
+		v := toVector(p)
+		distance := 0.0
+
+		if n := len(l.vectors); n > 0 {
+			distance = l.distances[n-1] + l.vectors[n-1].angle(v)
+		}
+
+		l.points = append(l.points, p)
+		l.vectors = append(l.vectors, v)
+		l.distances = append(l.distances, distance)
+	}
+
+	return l
+}
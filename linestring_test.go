@@ -0,0 +1,135 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineString_Nil(t *testing.T) {
+
+	var l *lineString
+	var ls LineString = l
+
+	assert.Nil(t, ls.Points())
+	assert.InDelta(t, 0, ls.Length(), 0)
+	assert.Nil(t, ls.PointAt(1))
+	assert.Nil(t, ls.PointAtFraction(0.5))
+	assert.Nil(t, ls.Boundary())
+	assert.Empty(t, ls.Reverse().Points())
+	assert.Empty(t, ls.Resample(1).Points())
+	assert.Empty(t, ls.Substring(0, 1).Points())
+
+	ls = NewLineString([]Point{nil})
+	assert.Empty(t, ls.Points())
+	assert.InDelta(t, 0, ls.Length(), 0)
+	assert.Nil(t, ls.PointAt(1))
+}
+
+func TestLineString_Length(t *testing.T) {
+
+	l := NewLineString([]Point{NewPoint(0, 179), NewPoint(0, -179), nil, NewPoint(1, -179)})
+
+	assert.Len(t, l.Points(), 3)
+	assert.InDelta(t, 3*111.195, l.Length(), 0.01)
+	assert.Equal(t, "[(0, 179) (0, -179) (1, -179)]", l.(*lineString).String())
+
+	l = NewLineString([]Point{NewPoint(45, 10)})
+	assert.InDelta(t, 0, l.Length(), 0)
+	assert.Equal(t, l.Points()[0], l.PointAt(100))
+}
+
+func TestLineString_PointAt(t *testing.T) {
+
+	l := NewLineString([]Point{NewPoint(0, 179), NewPoint(0, -179), NewPoint(1, -179)})
+
+	p := l.PointAt(111.195)
+	assert.InDelta(t, 0, p.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 180, p.Longitude(), 0.0001)
+
+	p = l.PointAtFraction(0.5)
+	assert.InDelta(t, 0, p.Latitude(), DecimalPrecision)
+	assert.InDelta(t, -179.5, p.Longitude(), 0.0001)
+
+	assert.Equal(t, l.Points()[0], l.PointAt(-1))
+	assert.Equal(t, l.Points()[1], l.PointAt(angleToKM(l.(*lineString).distances[1])))
+	assert.Equal(t, l.Points()[2], l.PointAtFraction(2))
+
+	// The great circle arc between two points on the same parallel bulges towards the pole.
+	l = NewLineString([]Point{NewPoint(60, -40), NewPoint(60, 40)})
+	assert.True(t, l.PointAtFraction(0.5).Latitude() > 64)
+}
+
+func TestLineString_Resample(t *testing.T) {
+
+	l := NewLineString([]Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1)})
+	r := l.Resample(50)
+
+	assert.Len(t, r.Points(), 6)
+	assert.Equal(t, l.Points()[2], r.Points()[5])
+
+	// Resampling cuts the corner between the points at 100km and 150km.
+	assert.True(t, r.Length() < l.Length())
+
+	for i, p := range r.Points()[:5] {
+		assert.Equal(t, l.PointAt(float64(i)*50), p)
+	}
+
+	assert.Len(t, l.Resample(0).Points(), 3)
+}
+
+func TestLineString_Reverse(t *testing.T) {
+
+	l := NewLineString([]Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1)})
+	r := l.Reverse()
+
+	assert.Equal(t, []Point{l.Points()[2], l.Points()[1], l.Points()[0]}, r.Points())
+	assert.InDelta(t, l.Length(), r.Length(), 1e-9)
+}
+
+func TestLineString_Boundary(t *testing.T) {
+
+	b := NewLineString([]Point{NewPoint(0, 179), NewPoint(0, -179), NewPoint(1, -179)}).Boundary()
+	assert.InDelta(t, 0, b.Lower().Latitude(), 0)
+	assert.InDelta(t, 179, b.Lower().Longitude(), 0)
+	assert.InDelta(t, 1, b.Upper().Latitude(), 0)
+	assert.InDelta(t, -179, b.Upper().Longitude(), 0)
+
+	b = NewLineString([]Point{NewPoint(60, -40), NewPoint(60, 40)}).Boundary()
+	assert.InDelta(t, 60, b.Lower().Latitude(), 0)
+	assert.True(t, b.Upper().Latitude() > 64)
+}
+
+func TestLineString_Substring(t *testing.T) {
+
+	l := NewLineString([]Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1)})
+
+	s := l.Substring(55.6, 166.8)
+	assert.Len(t, s.Points(), 3)
+	assert.InDelta(t, 111.2, s.Length(), 0.01)
+	assert.InDelta(t, 0.5, s.Points()[0].Longitude(), 0.001)
+	assert.Equal(t, l.Points()[1], s.Points()[1])
+	assert.InDelta(t, 0.5, s.Points()[2].Latitude(), 0.001)
+
+	r := l.Substring(166.8, 55.6)
+	assert.Equal(t, s.Reverse().Points(), r.Points())
+
+	s = l.Substring(-10, 1000)
+	assert.Equal(t, l.Points(), s.Points())
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"sort"
+)
+
+// Neighbour is a geo-location point found by a nearest neighbour search.
+type Neighbour struct {
+	// Index is the index of the point in the indexed points.
+	Index int
+	// Point is the point found.
+	Point Point
+	// Distance is the distance between the query point and the point found, as measured by the metric of the index,
+	// which is in kilometers for GetDistance.
+	Distance float64
+}
+
+// NeighbourOptions carries the options of the nearest neighbour searches.
+type NeighbourOptions struct {
+	// MaxDistance is the distance beyond which no points are found, as measured by the metric of the index,
+	// when it's zero there is no limit.
+	MaxDistance float64
+}
+
+// NeighbourIndex is an index of geo-location points answering the nearest neighbour searches.
+type NeighbourIndex interface {
+	// Points returns the indexed points.
+	Points() []Point
+	// Nearest returns the k points nearest to the given query point, as measured by the metric of the index,
+	// sorted by their distance, then by their index.
+	// Fewer points are returned if there aren't enough of them within the maximum distance.
+	Nearest(query Point, k int, options NeighbourOptions) []Neighbour
+}
+
+type neighbourIndex struct {
+	points []Point
+	tree   RTree[int]
+}
+
+func (x *neighbourIndex) Points() []Point {
+	if x != nil {
+		return x.points
+	}
+	return nil
+}
+
+func (x *neighbourIndex) Nearest(query Point, k int, options NeighbourOptions) []Neighbour {
+
+	found := []Neighbour{}
+
+	if x == nil || query == nil || k <= 0 {
+		return found
+	}
+
+	// The tree visits the points by their distance, so the search stops at the first point farther than the
+	// maximum distance, or farther than the kth point, since the points as far as the kth point are kept
+	// to break the ties by their index.
+	x.tree.Nearest(query, func(_ Boundary, i int, distance float64) bool {
+		if options.MaxDistance > 0 && distance > options.MaxDistance {
+			return false
+		}
+
+		if len(found) >= k && distance > found[k-1].Distance {
+			return false
+		}
+
+		found = append(found, Neighbour{Index: i, Point: x.points[i], Distance: distance})
+
+		return true
+	})
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Distance != found[j].Distance {
+			return found[i].Distance < found[j].Distance
+		}
+		return found[i].Index < found[j].Index
+	})
+
+	if len(found) > k {
+		found = found[:k]
+	}
+
+	return found
+}
+
+// NewNeighbourIndex creates a new nearest neighbour index of the given geo-location points, which are bulk loaded
+// into an R-tree searched best first, so a search only visits the tree nodes that may hold nearer points than
+// the ones found so far.
+// The metric of the index is GetDistance, under which the searches are exact, even across the antimeridian and
+// around the poles.
+// Nil points are left out of the searches.
+func NewNeighbourIndex(points []Point) NeighbourIndex {
+
+	entries := make([]RTreeEntry[int], 0, len(points))
+
+	for i, p := range points {
+		if p != nil {
+			entries = append(entries, RTreeEntry[int]{Boundary: NewBoundary(p, p), Value: i})
+		}
+	}
+
+	return &neighbourIndex{points: points, tree: NewRTree(entries...)}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNeighbourIndex(t *testing.T) {
+
+	var x *neighbourIndex
+	var nx NeighbourIndex = x
+
+	assert.Nil(t, nx.Points())
+	assert.Empty(t, nx.Nearest(NewPoint(0, 0), 1, NeighbourOptions{}))
+
+	points := []Point{NewPoint(0, 179.9), nil, NewPoint(0, -179.9), NewPoint(0, 170), NewPoint(90, 0), NewPoint(0, 179.9)}
+	nx = NewNeighbourIndex(points)
+
+	assert.Equal(t, points, nx.Points())
+	assert.Empty(t, nx.Nearest(nil, 1, NeighbourOptions{}))
+	assert.Empty(t, nx.Nearest(NewPoint(0, 180), 0, NeighbourOptions{}))
+
+	found := nx.Nearest(NewPoint(0, -179.95), 3, NeighbourOptions{})
+	assert.Len(t, found, 3)
+	assert.Equal(t, []int{2, 0, 5}, []int{found[0].Index, found[1].Index, found[2].Index})
+	assert.Equal(t, points[2], found[0].Point)
+	assert.Equal(t, GetDistance(NewPoint(0, -179.95), points[2]), found[0].Distance)
+
+	found = nx.Nearest(NewPoint(0, 180), 10, NeighbourOptions{MaxDistance: 100})
+	assert.Len(t, found, 3)
+
+	found = nx.Nearest(NewPoint(89.9, 123), 1, NeighbourOptions{})
+	assert.Equal(t, 4, found[0].Index)
+	assert.Equal(t, GetDistance(NewPoint(89.9, 123), NewPoint(90, 0)), found[0].Distance)
+}
+
+func TestNeighbourIndex_Random(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+	points := make([]Point, 3000)
+
+	for i := range points {
+		points[i] = getRandomPoint(r)
+	}
+
+	x := NewNeighbourIndex(points)
+
+	for i := 0; i < 300; i++ {
+		query := getRandomPoint(r)
+		k := 1 + r.Intn(20)
+		options := NeighbourOptions{}
+
+		if i%3 == 0 {
+			options.MaxDistance = []float64{1, 50, 500}[r.Intn(3)]
+		}
+
+		expected := []Neighbour{}
+
+		for j, p := range points {
+			if d := GetDistance(query, p); options.MaxDistance == 0 || d <= options.MaxDistance {
+				expected = append(expected, Neighbour{Index: j, Point: p, Distance: d})
+			}
+		}
+
+		sort.SliceStable(expected, func(a, b int) bool { return expected[a].Distance < expected[b].Distance })
+
+		if len(expected) > k {
+			expected = expected[:k]
+		}
+
+		assert.Equal(t, expected, x.Nearest(query, k, options), "%v %v %v", query, k, options)
+	}
+}
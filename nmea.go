@@ -0,0 +1,409 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidSentence is returned when a NMEA sentence is malformed or has invalid field values.
+	ErrInvalidSentence = errors.New("invalid nmea sentence")
+	// ErrInvalidChecksum is returned when a NMEA sentence has a missing or wrong checksum.
+	ErrInvalidChecksum = errors.New("invalid nmea checksum")
+	// ErrUnsupportedSentence is returned when a NMEA sentence is not one of GGA, RMC, GLL, VTG or GSA.
+	ErrUnsupportedSentence = errors.New("unsupported nmea sentence")
+	// ErrSentenceTooLong is returned when a line of a NMEA stream is too long to be a sentence.
+	ErrSentenceTooLong = errors.New("nmea sentence too long")
+)
+
+// knotToKMH is the conversion factor of speed from knots to kilometers per hour.
+const knotToKMH = 1.852
+
+// NMEAFix is the data carried by a NMEA 0183 sentence, where the values the sentence doesn't carry are left
+// empty, the numbers are NaN and the point is nil.
+type NMEAFix struct {
+	// Talker is the talker identifier, such as GP for GPS or GN for combined systems.
+	Talker string
+	// Type is the sentence type, one of GGA, RMC, GLL, VTG or GSA.
+	Type string
+	// Point is the position, carried by GGA, RMC and GLL sentences.
+	Point Point
+	// Time is the UTC time of the position, where only RMC sentences carry the date, the others have the time
+	// of the day on the first day of year zero.
+	Time time.Time
+	// Valid is whether the receiver reports the data as valid.
+	Valid bool
+	// Quality is the GGA fix quality, such as 1 for GPS or 4 for RTK.
+	Quality int
+	// Mode is the GSA fix mode, 1 for no fix, 2 for 2D and 3 for 3D.
+	Mode int
+	// Satellites is the number of satellites used, carried by GGA and GSA sentences.
+	Satellites int
+	// SatelliteIDs are the identifiers of the satellites used, carried by GSA sentences.
+	SatelliteIDs []int
+	// HDOP is the horizontal dilution of precision, carried by GGA and GSA sentences.
+	HDOP float64
+	// VDOP is the vertical dilution of precision, carried by GSA sentences.
+	VDOP float64
+	// PDOP is the position dilution of precision, carried by GSA sentences.
+	PDOP float64
+	// Altitude is the altitude above the mean sea level in meters, carried by GGA sentences.
+	Altitude float64
+	// Speed is the speed over ground in kilometers per hour, carried by RMC and VTG sentences.
+	Speed float64
+	// Course is the course over ground in degrees clockwise from the true north, carried by RMC and VTG sentences.
+	Course float64
+}
+
+// Fix returns the position as a fix, or nil if the sentence has no position.
+func (f *NMEAFix) Fix() Fix {
+
+	if f == nil || f.Point == nil {
+		return nil
+	}
+
+	return NewDetailedFix(f.Point, f.Time, math.NaN(), f.Altitude)
+}
+
+// getNMEAChecksum returns the checksum of the given sentence body, which is the exclusive or of all its bytes.
+func getNMEAChecksum(body string) byte {
+
+	var sum byte
+
+	for i := 0; i < len(body); i++ {
+		sum ^= body[i]
+	}
+
+	return sum
+}
+
+// nmeaFields parses the fields of a sentence, keeping the first error found.
+type nmeaFields struct {
+	values []string
+	err    error
+}
+
+func (f *nmeaFields) get(i int) string {
+	if i < len(f.values) {
+		return f.values[i]
+	}
+	return ""
+}
+
+func (f *nmeaFields) float(i int) float64 {
+
+	value := f.get(i)
+
+	if value == "" {
+		return math.NaN()
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+
+	if err != nil {
+		f.err = ErrInvalidSentence
+		return math.NaN()
+	}
+
+	return v
+}
+
+func (f *nmeaFields) int(i int) int {
+
+	value := f.get(i)
+
+	if value == "" {
+		return 0
+	}
+
+	v, err := strconv.Atoi(value)
+
+	if err != nil {
+		f.err = ErrInvalidSentence
+	}
+
+	return v
+}
+
+// angle returns the angle in degrees of the ddmm.mmmm value at i, with the hemisphere at i+1, where the
+// southern and western hemispheres are negative.
+func (f *nmeaFields) angle(i int, negative string) float64 {
+
+	value := f.float(i)
+
+	if math.IsNaN(value) {
+		return value
+	}
+
+	degrees := math.Floor(value / 100)
+	angle := degrees + (value-degrees*100)/60
+
+	switch f.get(i + 1) {
+	case negative:
+		return -angle
+	case "N", "E":
+		return angle
+	}
+
+	f.err = ErrInvalidSentence
+
+	return math.NaN()
+}
+
+// point returns the point of the latitude at i and the longitude at i+2, or nil if they're empty.
+func (f *nmeaFields) point(i int) Point {
+
+	lat := f.angle(i, "S")
+	lng := f.angle(i+2, "W")
+
+	if math.IsNaN(lat) || math.IsNaN(lng) {
+		return nil
+	}
+
+	if math.Abs(lat) > NorthPoleLat || math.Abs(lng) > HalfLongitude {
+		f.err = ErrInvalidSentence
+		return nil
+	}
+
+	return NewPoint(lat, lng)
+}
+
+// time returns the time of the day of the hhmmss.ss value at i, on the date of the ddmmyy value at date if
+// it's not negative.
+func (f *nmeaFields) time(i int, date int) time.Time {
+
+	value := f.get(i)
+
+	if value == "" {
+		return time.Time{}
+	}
+
+	seconds := f.float(i)
+
+	if len(value) < 6 || math.IsNaN(seconds) {
+		f.err = ErrInvalidSentence
+		return time.Time{}
+	}
+
+	hours, minutes := int(seconds/10000), int(math.Mod(seconds, 10000)/100)
+	nanoseconds := int(math.Round(math.Mod(seconds, 100) * 1e9))
+	year, month, day := 0, 1, 1
+
+	if date >= 0 && f.get(date) != "" {
+		d := f.get(date)
+		value, err := strconv.Atoi(d)
+
+		if len(d) != 6 || err != nil {
+			f.err = ErrInvalidSentence
+			return time.Time{}
+		}
+
+		day, month, year = value/10000, value/100%100, value%100
+
+		// The two digits year is taken within 1980 and 2079, GPS didn't exist before.
+		if year < 80 {
+			year += 2000
+		} else {
+			year += 1900
+		}
+	}
+
+	return time.Date(year, time.Month(month), day, hours, minutes, 0, nanoseconds, time.UTC)
+}
+
+// ParseNMEA parses a single NMEA 0183 sentence, such as "$GPGGA,...*47", checking its checksum.
+func ParseNMEA(sentence string) (*NMEAFix, error) {
+
+	sentence = strings.TrimSpace(sentence)
+
+	if !strings.HasPrefix(sentence, "$") {
+		return nil, ErrInvalidSentence
+	}
+
+	star := strings.LastIndexByte(sentence, '*')
+
+	if star < 0 || len(sentence)-star != 3 {
+		return nil, ErrInvalidChecksum
+	}
+
+	body := sentence[1:star]
+	checksum, err := strconv.ParseUint(sentence[star+1:], 16, 8)
+
+	if err != nil || byte(checksum) != getNMEAChecksum(body) {
+		return nil, ErrInvalidChecksum
+	}
+
+	values := strings.Split(body, ",")
+
+	if len(values[0]) != 5 {
+		return nil, ErrInvalidSentence
+	}
+
+	fix := &NMEAFix{
+		Talker:   values[0][:2],
+		Type:     values[0][2:],
+		HDOP:     math.NaN(),
+		VDOP:     math.NaN(),
+		PDOP:     math.NaN(),
+		Altitude: math.NaN(),
+		Speed:    math.NaN(),
+		Course:   math.NaN(),
+	}
+
+	f := &nmeaFields{values: values[1:]}
+
+	switch fix.Type {
+	case "GGA":
+		fix.Time = f.time(0, -1)
+		fix.Point = f.point(1)
+		fix.Quality = f.int(5)
+		fix.Valid = fix.Quality > 0
+		fix.Satellites = f.int(6)
+		fix.HDOP = f.float(7)
+		fix.Altitude = f.float(8)
+	case "RMC":
+		fix.Time = f.time(0, 8)
+		fix.Valid = f.get(1) == "A"
+		fix.Point = f.point(2)
+		fix.Speed = f.float(6) * knotToKMH
+		fix.Course = f.float(7)
+	case "GLL":
+		fix.Point = f.point(0)
+		fix.Time = f.time(4, -1)
+		fix.Valid = f.get(5) == "A"
+	case "VTG":
+		fix.Course = f.float(0)
+		fix.Speed = f.float(6)
+
+		if math.IsNaN(fix.Speed) {
+			fix.Speed = f.float(4) * knotToKMH
+		}
+
+		fix.Valid = f.get(8) != "N"
+	case "GSA":
+		fix.Mode = f.int(1)
+		fix.Valid = fix.Mode >= 2
+
+		for i := 2; i < 14; i++ {
+			if f.get(i) != "" {
+				fix.SatelliteIDs = append(fix.SatelliteIDs, f.int(i))
+			}
+		}
+
+		fix.Satellites = len(fix.SatelliteIDs)
+		fix.PDOP = f.float(14)
+		fix.HDOP = f.float(15)
+		fix.VDOP = f.float(16)
+	default:
+		return nil, ErrUnsupportedSentence
+	}
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return fix, nil
+}
+
+// NMEAError is a sentence that couldn't be parsed, which doesn't stop decoding the sentences after it.
+type NMEAError struct {
+	// Line is the line number of the sentence.
+	Line int
+	// Err is the reason of the failure.
+	Err error
+}
+
+func (e *NMEAError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *NMEAError) Unwrap() error {
+	return e.Err
+}
+
+// nmeaMaxLine is the longest line the decoder reads as a sentence, well beyond the 82 characters allowed by the
+// standard, so that the longer lines are only garbage.
+const nmeaMaxLine = 4096
+
+// NMEADecoder decodes the NMEA 0183 sentences of a stream, one sentence per line.
+type NMEADecoder struct {
+	reader *bufio.Reader
+	line   int
+}
+
+// NewNMEADecoder creates a new decoder of the sentences of the given stream.
+func NewNMEADecoder(r io.Reader) *NMEADecoder {
+	return &NMEADecoder{reader: bufio.NewReaderSize(r, nmeaMaxLine)}
+}
+
+// Decode returns the next sentence of the stream, skipping the empty lines, or io.EOF once there are no more
+// sentences.
+// A sentence that can't be parsed is returned as a NMEAError, after which decoding can go on with the next
+// sentences, while any other error means the stream is broken.
+// A line too long to be a sentence, such as a burst of binary garbage, is skipped as a NMEAError of
+// ErrSentenceTooLong.
+func (d *NMEADecoder) Decode() (*NMEAFix, error) {
+
+	for {
+		content, err := d.reader.ReadSlice('\n')
+
+		if err == bufio.ErrBufferFull {
+			d.line++
+
+			for err == bufio.ErrBufferFull {
+				_, err = d.reader.ReadSlice('\n')
+			}
+
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+
+			return nil, &NMEAError{Line: d.line, Err: ErrSentenceTooLong}
+		}
+
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if len(content) == 0 {
+			return nil, io.EOF
+		}
+
+		d.line++
+		line := strings.TrimSpace(string(content))
+
+		if line == "" {
+			continue
+		}
+
+		fix, err := ParseNMEA(line)
+
+		if err != nil {
+			return nil, &NMEAError{Line: d.line, Err: err}
+		}
+
+		return fix, nil
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNMEA_GGA(t *testing.T) {
+
+	fix, err := ParseNMEA("$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47\r\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "GP", fix.Talker)
+	assert.Equal(t, "GGA", fix.Type)
+	assert.InDelta(t, 48+7.038/60, fix.Point.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 11+31.0/60, fix.Point.Longitude(), DecimalPrecision)
+	assert.Equal(t, time.Date(0, 1, 1, 12, 35, 19, 0, time.UTC), fix.Time)
+	assert.True(t, fix.Valid)
+	assert.Equal(t, 1, fix.Quality)
+	assert.Equal(t, 8, fix.Satellites)
+	assert.Equal(t, 0.9, fix.HDOP)
+	assert.Equal(t, 545.4, fix.Altitude)
+	assert.True(t, math.IsNaN(fix.Speed))
+	assert.True(t, math.IsNaN(fix.Course))
+
+	altitude, ok := fix.Fix().Altitude()
+	assert.True(t, ok)
+	assert.Equal(t, 545.4, altitude)
+	assert.Equal(t, fix.Point, fix.Fix().Point())
+}
+
+func TestParseNMEA_RMC(t *testing.T) {
+
+	fix, err := ParseNMEA("$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6A")
+	assert.Nil(t, err)
+	assert.Equal(t, "RMC", fix.Type)
+	assert.True(t, fix.Valid)
+	assert.Equal(t, time.Date(1994, 3, 23, 12, 35, 19, 0, time.UTC), fix.Time)
+	assert.InDelta(t, 22.4*1.852, fix.Speed, DecimalPrecision)
+	assert.Equal(t, 84.4, fix.Course)
+	assert.NotNil(t, fix.Point)
+
+	// No fix yet.
+	fix, err = ParseNMEA("$GNRMC,000000.50,V,,,,,,,010100,,,N*66")
+	assert.Nil(t, err)
+	assert.Equal(t, "GN", fix.Talker)
+	assert.False(t, fix.Valid)
+	assert.Nil(t, fix.Point)
+	assert.Equal(t, time.Date(2000, 1, 1, 0, 0, 0, 5e8, time.UTC), fix.Time)
+}
+
+func TestParseNMEA_GLL(t *testing.T) {
+
+	fix, err := ParseNMEA("$GPGLL,4916.45,N,12311.12,W,225444,A*31")
+	assert.Nil(t, err)
+	assert.InDelta(t, 49+16.45/60, fix.Point.Latitude(), DecimalPrecision)
+	assert.InDelta(t, -(123 + 11.12/60), fix.Point.Longitude(), DecimalPrecision)
+	assert.Equal(t, time.Date(0, 1, 1, 22, 54, 44, 0, time.UTC), fix.Time)
+	assert.True(t, fix.Valid)
+}
+
+func TestParseNMEA_VTG(t *testing.T) {
+
+	fix, err := ParseNMEA("$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K*48")
+	assert.Nil(t, err)
+	assert.Equal(t, 54.7, fix.Course)
+	assert.Equal(t, 10.2, fix.Speed)
+	assert.Nil(t, fix.Point)
+	assert.Nil(t, fix.Fix())
+
+	// The speed in knots is used when the one in kilometers per hour is missing.
+	fix, err = ParseNMEA("$GPVTG,054.7,T,034.4,M,005.5,N,,K*65")
+	assert.Nil(t, err)
+	assert.InDelta(t, 5.5*1.852, fix.Speed, DecimalPrecision)
+}
+
+func TestParseNMEA_GSA(t *testing.T) {
+
+	fix, err := ParseNMEA("$GPGSA,A,3,04,05,,09,12,,,24,,,,,2.5,1.3,2.1*39")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, fix.Mode)
+	assert.True(t, fix.Valid)
+	assert.Equal(t, []int{4, 5, 9, 12, 24}, fix.SatelliteIDs)
+	assert.Equal(t, 5, fix.Satellites)
+	assert.Equal(t, 2.5, fix.PDOP)
+	assert.Equal(t, 1.3, fix.HDOP)
+	assert.Equal(t, 2.1, fix.VDOP)
+}
+
+func TestParseNMEA_Errors(t *testing.T) {
+
+	for sentence, expected := range map[string]error{
+		"":                                  ErrInvalidSentence,
+		"GPGGA,123519*47":                   ErrInvalidSentence,
+		"$GPGGA,123519,4807.038,N":          ErrInvalidChecksum,
+		"$GPVTG,054.7,T,034.4,M,005.5,N*00": ErrInvalidChecksum,
+		"$GPVTG,054.7,T,034.4,M,005.5,N*4":  ErrInvalidChecksum,
+		"$GPGSV,3,1,11,03,03,111,00*4A":     ErrUnsupportedSentence,
+		"$GPGGA,1235,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*4F":   ErrInvalidSentence,
+		"$GPGGA,123519,9107.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*43": ErrInvalidSentence,
+		"$GPGGA,123519,4807.038,X,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*51": ErrInvalidSentence,
+	} {
+		_, err := ParseNMEA(sentence)
+		assert.Equal(t, expected, err, sentence)
+	}
+}
+
+func TestNMEADecoder(t *testing.T) {
+
+	stream := "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47\r\n" +
+		"\r\n" +
+		"$GPGSV,3,1,11,03,03,111,00*4A\r\n" +
+		"$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6A\r\n"
+
+	d := NewNMEADecoder(strings.NewReader(stream))
+
+	fix, err := d.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, "GGA", fix.Type)
+
+	_, err = d.Decode()
+	var sentenceErr *NMEAError
+	assert.True(t, errors.As(err, &sentenceErr))
+	assert.Equal(t, 3, sentenceErr.Line)
+	assert.True(t, errors.Is(err, ErrUnsupportedSentence))
+	assert.Equal(t, "line 3: unsupported nmea sentence", err.Error())
+
+	fix, err = d.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, "RMC", fix.Type)
+
+	_, err = d.Decode()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestNMEADecoder_TooLong(t *testing.T) {
+
+	gga := "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47"
+	garbage := strings.Repeat("\x00\xff", 40000)
+	stream := gga + "\r\n" + garbage + "\r\n" + gga + "\r\n" + garbage
+
+	d := NewNMEADecoder(strings.NewReader(stream))
+
+	fix, err := d.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, "GGA", fix.Type)
+
+	// The garbage line is skipped, and decoding goes on with the next sentence.
+	_, err = d.Decode()
+	var sentenceErr *NMEAError
+	assert.True(t, errors.As(err, &sentenceErr))
+	assert.Equal(t, 2, sentenceErr.Line)
+	assert.True(t, errors.Is(err, ErrSentenceTooLong))
+
+	fix, err = d.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, "GGA", fix.Type)
+
+	// Even at the end of the stream without a line break.
+	_, err = d.Decode()
+	assert.True(t, errors.Is(err, ErrSentenceTooLong))
+
+	_, err = d.Decode()
+	assert.Equal(t, io.EOF, err)
+
+	// The last sentence doesn't need a line break.
+	d = NewNMEADecoder(strings.NewReader(gga))
+
+	fix, err = d.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, "GGA", fix.Type)
+
+	_, err = d.Decode()
+	assert.Equal(t, io.EOF, err)
+}
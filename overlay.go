@@ -0,0 +1,618 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+// overlayTolerance is the distance on the projection plane, which is about the same in radians around its
+// center, below which two vertices are considered the same.
+const overlayTolerance = 1e-10
+
+// overlayOperation identifies one of the polygon boolean operations.
+type overlayOperation byte
+
+const (
+	overlayUnion overlayOperation = iota
+	overlayIntersection
+	overlayDifference
+	overlaySymmetricDifference
+)
+
+type planePoint struct {
+	x, y float64
+}
+
+func (p planePoint) sub(q planePoint) planePoint {
+	return planePoint{p.x - q.x, p.y - q.y}
+}
+
+func (p planePoint) cross(q planePoint) float64 {
+	return p.x*q.y - p.y*q.x
+}
+
+func (p planePoint) dot(q planePoint) float64 {
+	return p.x*q.x + p.y*q.y
+}
+
+func (p planePoint) norm() float64 {
+	return math.Hypot(p.x, p.y)
+}
+
+// overlayEdge is a directed edge between two overlay vertices, belonging to one of the two operands.
+type overlayEdge struct {
+	from, to int
+	operand  int
+}
+
+// overlay computes the boolean operations of two areas, both projected on the same gnomonic plane, where the
+// straight edges are the great circle arcs of the sphere.
+// It splits all the edges at their intersections, classifies each piece as inside or outside of the other
+// operand, or shared with it, then links the pieces selected by the operation into the result rings.
+type overlay struct {
+	projection gnomonic
+	vertices   []planePoint
+	grid       map[[2]int64][]int
+	rings      [2][][]int
+}
+
+// vertex returns the identifier of the vertex at the given point, reusing any existing vertex within the
+// tolerance from it.
+func (o *overlay) vertex(p planePoint) int {
+
+	cx, cy := int64(math.Floor(p.x/overlayTolerance)), int64(math.Floor(p.y/overlayTolerance))
+
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for _, id := range o.grid[[2]int64{cx + dx, cy + dy}] {
+				if o.vertices[id].sub(p).norm() <= overlayTolerance {
+					return id
+				}
+			}
+		}
+	}
+
+	id := len(o.vertices)
+	o.vertices = append(o.vertices, p)
+	o.grid[[2]int64{cx, cy}] = append(o.grid[[2]int64{cx, cy}], id)
+
+	return id
+}
+
+// add adds the rings of the given polygons as one of the two operands, making the shells counter clockwise and
+// the holes clockwise on the plane.
+func (o *overlay) add(operand int, polygons []Polygon) {
+
+	for _, p := range polygons {
+		for r, points := range append([][]Point{p.Shell()}, p.Holes()...) {
+			ring := []int{}
+
+			for _, v := range getRingVectors(points) {
+				x, y := o.projection.project(v)
+				id := o.vertex(planePoint{x, y})
+
+				if n := len(ring); n == 0 || ring[n-1] != id {
+					ring = append(ring, id)
+				}
+			}
+
+			if n := len(ring); n > 1 && ring[0] == ring[n-1] {
+				ring = ring[:n-1]
+			}
+
+			if len(ring) < 3 {
+				continue
+			}
+
+			if (o.area(ring) > 0) != (r == 0) {
+				for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+					ring[i], ring[j] = ring[j], ring[i]
+				}
+			}
+
+			o.rings[operand] = append(o.rings[operand], ring)
+		}
+	}
+}
+
+// area returns the signed area of the given ring on the plane.
+func (o *overlay) area(ring []int) float64 {
+
+	sum := 0.0
+
+	for i := range ring {
+		p, q := o.vertices[ring[i]], o.vertices[ring[(i+1)%len(ring)]]
+		sum += p.cross(q)
+	}
+
+	return sum / 2
+}
+
+// contains reports whether the given point is inside the given operand, following the even-odd rule.
+func (o *overlay) contains(operand int, p planePoint) bool {
+
+	inside := false
+
+	for _, ring := range o.rings[operand] {
+		for i := range ring {
+			a, b := o.vertices[ring[i]], o.vertices[ring[(i+1)%len(ring)]]
+
+			if (a.y > p.y) != (b.y > p.y) && p.x < (b.x-a.x)*(p.y-a.y)/(b.y-a.y)+a.x {
+				inside = !inside
+			}
+		}
+	}
+
+	return inside
+}
+
+// split returns the edges of both operands split at all their intersections.
+func (o *overlay) split() []overlayEdge {
+
+	edges := []overlayEdge{}
+
+	for operand, rings := range o.rings {
+		for _, ring := range rings {
+			for i := range ring {
+				edges = append(edges, overlayEdge{from: ring[i], to: ring[(i+1)%len(ring)], operand: operand})
+			}
+		}
+	}
+
+	nodes := make([][]int, len(edges))
+
+	// onEdge records the vertex as a node of the edge if it's strictly between its ends.
+	onEdge := func(e int, id int) {
+		if id != edges[e].from && id != edges[e].to {
+			nodes[e] = append(nodes[e], id)
+		}
+	}
+
+	// Sweeping the edges along the x axis, so that only the edges overlapping on it are compared.
+	minX := func(e overlayEdge) float64 {
+		return math.Min(o.vertices[e.from].x, o.vertices[e.to].x)
+	}
+
+	order := makeRange(len(edges))
+	sort.Slice(order, func(a, b int) bool {
+		return minX(edges[order[a]]) < minX(edges[order[b]])
+	})
+
+	for a, i := range order {
+		p, p2 := o.vertices[edges[i].from], o.vertices[edges[i].to]
+		d1 := p2.sub(p)
+
+		for _, j := range order[a+1:] {
+			q, q2 := o.vertices[edges[j].from], o.vertices[edges[j].to]
+			d2 := q2.sub(q)
+
+			if math.Max(p.x, p2.x)+overlayTolerance < minX(edges[j]) {
+				break
+			}
+
+			// Skipping the edges that are far apart.
+			if math.Max(p.y, p2.y)+overlayTolerance < math.Min(q.y, q2.y) ||
+				math.Max(q.y, q2.y)+overlayTolerance < math.Min(p.y, p2.y) {
+				continue
+			}
+
+			// The ends of each edge lying on the other one are nodes of it, which covers both touching and
+			// overlapping collinear edges.
+			for _, end := range []int{edges[j].from, edges[j].to} {
+				if o.onSegment(o.vertices[end], p, p2) {
+					onEdge(i, end)
+				}
+			}
+
+			for _, end := range []int{edges[i].from, edges[i].to} {
+				if o.onSegment(o.vertices[end], q, q2) {
+					onEdge(j, end)
+				}
+			}
+
+			denominator := d1.cross(d2)
+
+			if math.Abs(denominator) <= 1e-14*d1.norm()*d2.norm() {
+				continue
+			}
+
+			t := q.sub(p).cross(d2) / denominator
+			u := q.sub(p).cross(d1) / denominator
+
+			if t <= 0 || t >= 1 || u <= 0 || u >= 1 {
+				continue
+			}
+
+			id := o.vertex(planePoint{p.x + t*d1.x, p.y + t*d1.y})
+			onEdge(i, id)
+			onEdge(j, id)
+		}
+	}
+
+	result := []overlayEdge{}
+
+	for i, e := range edges {
+		from := o.vertices[e.from]
+		d := o.vertices[e.to].sub(from)
+		list := nodes[i]
+
+		sort.Slice(list, func(a, b int) bool {
+			return o.vertices[list[a]].sub(from).dot(d) < o.vertices[list[b]].sub(from).dot(d)
+		})
+
+		prev := e.from
+
+		for _, id := range append(list, e.to) {
+			if id != prev {
+				result = append(result, overlayEdge{from: prev, to: id, operand: e.operand})
+				prev = id
+			}
+		}
+	}
+
+	return result
+}
+
+// onSegment reports whether the point is within the tolerance from the segment between a and b.
+func (o *overlay) onSegment(p, a, b planePoint) bool {
+
+	d := b.sub(a)
+	length := d.norm()
+
+	if length == 0 {
+		return false
+	}
+
+	along := p.sub(a).dot(d) / length
+
+	return along >= -overlayTolerance && along <= length+overlayTolerance &&
+		math.Abs(p.sub(a).cross(d))/length <= overlayTolerance
+}
+
+// selectEdges returns the directed edges bounding the result of the given operation.
+func (o *overlay) selectEdges(edges []overlayEdge, operation overlayOperation) []overlayEdge {
+
+	type key struct {
+		from, to int
+	}
+
+	// Edges of the same operand going both ways are internal to it and cancel out, such as the shared edge
+	// of two adjacent polygons, while the duplicates going the same way are kept once.
+	counts := [2]map[key]int{make(map[key]int), make(map[key]int)}
+
+	for _, e := range edges {
+		counts[e.operand][key{e.from, e.to}]++
+	}
+
+	directed := [2]map[key]bool{make(map[key]bool), make(map[key]bool)}
+
+	for operand, c := range counts {
+		for k := range c {
+			if c[key{k.to, k.from}] == 0 {
+				directed[operand][k] = true
+			}
+		}
+	}
+
+	result := []overlayEdge{}
+	keep := func(from, to int) {
+		result = append(result, overlayEdge{from: from, to: to})
+	}
+
+	for operand, set := range directed {
+		other := 1 - operand
+
+		for k := range set {
+			if directed[other][k] {
+				// Shared edge going the same way, the areas are on the same side of it.
+				if operand == 0 && (operation == overlayUnion || operation == overlayIntersection) {
+					keep(k.from, k.to)
+				}
+				continue
+			}
+
+			if directed[other][key{k.to, k.from}] {
+				// Shared edge going opposite ways, the areas are on the opposite sides of it.
+				if operand == 0 && operation == overlayDifference {
+					keep(k.from, k.to)
+				}
+				continue
+			}
+
+			a, b := o.vertices[k.from], o.vertices[k.to]
+			inside := o.contains(other, planePoint{(a.x + b.x) / 2, (a.y + b.y) / 2})
+
+			switch {
+			case operation == overlayUnion && !inside,
+				operation == overlayIntersection && inside,
+				operation == overlayDifference && operand == 0 && !inside,
+				operation == overlaySymmetricDifference && !inside:
+				keep(k.from, k.to)
+			case operation == overlayDifference && operand == 1 && inside,
+				operation == overlaySymmetricDifference && inside:
+				keep(k.to, k.from)
+			}
+		}
+	}
+
+	return result
+}
+
+// link joins the given directed edges into rings, keeping the area on the left side of each ring.
+func (o *overlay) link(edges []overlayEdge) [][]int {
+
+	outgoing := make(map[int][]int)
+
+	// Sorting the edges keeps the results reproducible, since they were collected out of maps.
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].from == edges[j].from {
+			return edges[i].to < edges[j].to
+		}
+		return edges[i].from < edges[j].from
+	})
+
+	for i, e := range edges {
+		outgoing[e.from] = append(outgoing[e.from], i)
+	}
+
+	used := make([]bool, len(edges))
+	rings := [][]int{}
+
+	for start := range edges {
+		if used[start] {
+			continue
+		}
+
+		ring := []int{}
+		current := start
+
+		for current != -1 && !used[current] {
+			used[current] = true
+			e := edges[current]
+			ring = append(ring, e.from)
+
+			if e.to == edges[start].from {
+				break
+			}
+
+			// Taking the sharpest left turn at the shared vertices, so that areas touching at a vertex
+			// end up in separate rings.
+			incoming := o.vertices[e.to].sub(o.vertices[e.from])
+			next, nextTurn := -1, math.Inf(-1)
+
+			for _, candidate := range outgoing[e.to] {
+				if used[candidate] {
+					continue
+				}
+
+				out := o.vertices[edges[candidate].to].sub(o.vertices[e.to])
+				turn := math.Atan2(incoming.cross(out), incoming.dot(out))
+
+				if turn > nextTurn {
+					next, nextTurn = candidate, turn
+				}
+			}
+
+			current = next
+		}
+
+		if len(ring) >= 3 && math.Abs(o.area(ring)) > overlayTolerance*overlayTolerance {
+			rings = append(rings, ring)
+		}
+	}
+
+	return rings
+}
+
+// build returns the polygons made of the given rings, where the counter clockwise rings are shells and the
+// clockwise ones are holes of the smallest shell containing them.
+func (o *overlay) build(rings [][]int) MultiPolygon {
+
+	shells := [][]int{}
+	holes := [][]int{}
+
+	for _, ring := range rings {
+		if o.area(ring) > 0 {
+			shells = append(shells, ring)
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+
+	owned := make([][][]int, len(shells))
+
+	for _, hole := range holes {
+		a, b := o.vertices[hole[0]], o.vertices[hole[1]]
+		middle := planePoint{(a.x + b.x) / 2, (a.y + b.y) / 2}
+		owner, ownerArea := -1, math.Inf(1)
+
+		for i, shell := range shells {
+			if area := o.area(shell); area < ownerArea && o.ringContains(shell, middle) {
+				owner, ownerArea = i, area
+			}
+		}
+
+		if owner != -1 {
+			owned[owner] = append(owned[owner], hole)
+		}
+	}
+
+	toPoints := func(ring []int) []Point {
+		points := make([]Point, 0, len(ring)+1)
+
+		for _, id := range ring {
+			p := o.vertices[id]
+			points = append(points, o.projection.unproject(p.x, p.y).toPoint())
+		}
+
+		return append(points, points[0])
+	}
+
+	polygons := []Polygon{}
+
+	for i, shell := range shells {
+		hs := [][]Point{}
+
+		for _, h := range owned[i] {
+			hs = append(hs, toPoints(h))
+		}
+
+		polygons = append(polygons, NewPolygon(toPoints(shell), hs...))
+	}
+
+	return NewMultiPolygon(polygons...)
+}
+
+// ringContains reports whether the given point is inside the given ring on the plane.
+func (o *overlay) ringContains(ring []int, p planePoint) bool {
+
+	inside := false
+
+	for i := range ring {
+		a, b := o.vertices[ring[i]], o.vertices[ring[(i+1)%len(ring)]]
+
+		if (a.y > p.y) != (b.y > p.y) && p.x < (b.x-a.x)*(p.y-a.y)/(b.y-a.y)+a.x {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// getOverlay runs the given operation on the two given areas.
+func getOverlay(a, b MultiPolygon, operation overlayOperation) (MultiPolygon, error) {
+
+	vectors := []vector{}
+
+	for _, m := range []MultiPolygon{a, b} {
+		for _, p := range m.Polygons() {
+			for _, r := range append([][]Point{p.Shell()}, p.Holes()...) {
+				vectors = append(vectors, getRingVectors(r)...)
+			}
+		}
+	}
+
+	if len(vectors) == 0 {
+		return NewMultiPolygon(), nil
+	}
+
+	c, err := getEnclosingCap(vectors)
+
+	if err != nil {
+		return nil, err
+	}
+
+	o := &overlay{projection: newGnomonic(c.center), grid: make(map[[2]int64][]int)}
+	o.add(0, a.Polygons())
+	o.add(1, b.Polygons())
+
+	return o.build(o.link(o.selectEdges(o.split(), operation))), nil
+}
+
+// GetUnion returns the area covered by any of the two given areas.
+// The operations are done on the plane of the gnomonic projection around the areas, where the great circle arcs
+// are straight lines, so both areas have to fit in a single hemisphere, otherwise ErrNotInHemisphere is returned.
+// The areas are expected to be valid, as reported by ValidatePolygon, while touching and overlapping edges are
+// handled.
+func GetUnion(a, b MultiPolygon) (MultiPolygon, error) {
+	return getOverlay(a, b, overlayUnion)
+}
+
+// GetIntersection returns the area covered by both of the two given areas.
+// It has the same requirements as GetUnion.
+func GetIntersection(a, b MultiPolygon) (MultiPolygon, error) {
+	return getOverlay(a, b, overlayIntersection)
+}
+
+// GetDifference returns the area covered by the first given area but not by the second one.
+// It has the same requirements as GetUnion.
+func GetDifference(a, b MultiPolygon) (MultiPolygon, error) {
+	return getOverlay(a, b, overlayDifference)
+}
+
+// GetSymmetricDifference returns the area covered by only one of the two given areas, which is the exclusive or
+// of them.
+// It has the same requirements as GetUnion.
+func GetSymmetricDifference(a, b MultiPolygon) (MultiPolygon, error) {
+	return getOverlay(a, b, overlaySymmetricDifference)
+}
+
+// NewBoundaryPolygon creates a new polygon covering the given boundary.
+// Unlike the polygon edges, the northern and southern sides of a boundary follow the parallels, so they are split
+// into arcs of a degree of longitude at most to follow them closely.
+// A boundary touching a pole becomes a polygon having that pole as a vertex, or a polygon around that pole if it goes
+// around earth.
+// A polygon always bounds the smaller one of the two areas its ring divides the surface of earth into, so a boundary
+// covering a hemisphere or more, touching both poles or going around earth without touching a pole has no polygon,
+// in which case nil is returned.
+func NewBoundaryPolygon(b Boundary) Polygon {
+
+	if b == nil {
+		return nil
+	}
+
+	lower, upper := b.Lower(), b.Upper()
+	minLat, maxLat := lower.Latitude(), upper.Latitude()
+	minLng, maxLng := lower.Longitude(), upper.Longitude()
+
+	parallel := func(lat, from, to float64) []Point {
+		count := int(math.Ceil(math.Abs(to - from)))
+		points := []Point{}
+
+		for i := 0; i <= count; i++ {
+			points = append(points, NewPoint(lat, from+(to-from)*float64(i)/math.Max(1, float64(count))))
+		}
+
+		return points
+	}
+
+	if maxLng <= minLng {
+		maxLng += TotalLongitude
+	}
+
+	// The area of the boundary on the unit sphere is its width in radians times the difference of the sines of its
+	// latitudes, while a hemisphere is 2 pi.
+	if (maxLng-minLng)*radians*(math.Sin(maxLat*radians)-math.Sin(minLat*radians)) >= 2*math.Pi {
+		return nil
+	}
+
+	northPole := maxLat == NorthPoleLat
+	southPole := minLat == SouthPoleLat
+	around := maxLng-minLng >= TotalLongitude
+
+	switch {
+	case northPole && southPole:
+		return nil
+	case northPole && around:
+		return NewPolygon(closeRing(parallel(minLat, -HalfLongitude, HalfLongitude)[1:]))
+	case southPole && around:
+		return NewPolygon(closeRing(parallel(maxLat, HalfLongitude, -HalfLongitude)[1:]))
+	case around:
+		return nil
+	case northPole:
+		return NewPolygon(closeRing(append(parallel(minLat, minLng, maxLng), NewPoint(NorthPoleLat, minLng))))
+	case southPole:
+		return NewPolygon(closeRing(append(parallel(maxLat, maxLng, minLng), NewPoint(SouthPoleLat, minLng))))
+	}
+
+	shell := parallel(minLat, minLng, maxLng)
+	shell = append(shell, parallel(maxLat, maxLng, minLng)...)
+
+	return NewPolygon(closeRing(shell))
+}
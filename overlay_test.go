@@ -0,0 +1,238 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOverlay_Overlapping(t *testing.T) {
+
+	a := NewMultiPolygon(NewPolygon(getSquare(0, 0, 1)))
+	b := NewMultiPolygon(NewPolygon(getSquare(0.5, 0, 1)))
+	// The meridian sides are shared, so the pieces are made of the sides of both squares.
+	bottom := NewPolygon([]Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(0.5, 1), NewPoint(0.5, 0), NewPoint(0, 0)})
+	middle := NewPolygon([]Point{NewPoint(0.5, 0), NewPoint(0.5, 1), NewPoint(1, 1), NewPoint(1, 0), NewPoint(0.5, 0)})
+
+	union, err := GetUnion(a, b)
+	assert.Nil(t, err)
+	assert.Len(t, union.Polygons(), 1)
+	assert.InDelta(t, a.Area()+b.Area()-middle.Area(), union.Area(), 1e-6)
+	assert.True(t, union.Contains(NewPoint(1.25, 0.5)))
+
+	intersection, err := GetIntersection(a, b)
+	assert.Nil(t, err)
+	assert.Len(t, intersection.Polygons(), 1)
+	assert.InDelta(t, middle.Area(), intersection.Area(), 1e-6)
+	assert.True(t, intersection.Contains(NewPoint(0.75, 0.5)))
+	assert.False(t, intersection.Contains(NewPoint(0.25, 0.5)))
+
+	difference, err := GetDifference(a, b)
+	assert.Nil(t, err)
+	assert.Len(t, difference.Polygons(), 1)
+	assert.InDelta(t, bottom.Area(), difference.Area(), 1e-6)
+	assert.True(t, difference.Contains(NewPoint(0.25, 0.5)))
+	assert.False(t, difference.Contains(NewPoint(0.75, 0.5)))
+
+	xor, err := GetSymmetricDifference(a, b)
+	assert.Nil(t, err)
+	assert.Len(t, xor.Polygons(), 2)
+	assert.InDelta(t, a.Area()+b.Area()-2*middle.Area(), xor.Area(), 1e-6)
+	assert.False(t, xor.Contains(NewPoint(0.75, 0.5)))
+	assert.True(t, xor.Contains(NewPoint(1.25, 0.5)))
+
+	for _, m := range []MultiPolygon{union, intersection, difference, xor} {
+		for _, p := range m.Polygons() {
+			assert.Empty(t, ValidatePolygon(p))
+		}
+	}
+}
+
+func TestGetOverlay_Touching(t *testing.T) {
+
+	a := NewMultiPolygon(NewPolygon(getSquare(0, 0, 1)))
+
+	// Sharing a whole edge.
+	b := NewMultiPolygon(NewPolygon(getSquare(0, 1, 1)))
+
+	union, err := GetUnion(a, b)
+	assert.Nil(t, err)
+	assert.Len(t, union.Polygons(), 1)
+	assert.InDelta(t, a.Area()+b.Area(), union.Area(), 1e-6)
+
+	intersection, err := GetIntersection(a, b)
+	assert.Nil(t, err)
+	assert.Empty(t, intersection.Polygons())
+
+	difference, err := GetDifference(a, b)
+	assert.Nil(t, err)
+	assert.Len(t, difference.Polygons(), 1)
+	assert.InDelta(t, a.Area(), difference.Area(), 1e-6)
+
+	// Sharing a part of an edge, with the vertices of each on the edge of the other.
+	b = NewMultiPolygon(NewPolygon(getSquare(0.5, 1, 1)))
+
+	union, err = GetUnion(a, b)
+	assert.Nil(t, err)
+	assert.Len(t, union.Polygons(), 1)
+	assert.InDelta(t, a.Area()+b.Area(), union.Area(), 1e-6)
+
+	// Sharing a vertex only.
+	b = NewMultiPolygon(NewPolygon(getSquare(1, 1, 1)))
+
+	union, err = GetUnion(a, b)
+	assert.Nil(t, err)
+	assert.Len(t, union.Polygons(), 2)
+	assert.InDelta(t, a.Area()+b.Area(), union.Area(), 1e-6)
+
+	// Identical operands.
+	union, err = GetUnion(a, a)
+	assert.Nil(t, err)
+	assert.Len(t, union.Polygons(), 1)
+	assert.InDelta(t, a.Area(), union.Area(), 1e-6)
+
+	difference, err = GetDifference(a, a)
+	assert.Nil(t, err)
+	assert.Empty(t, difference.Polygons())
+
+	xor, err := GetSymmetricDifference(a, a)
+	assert.Nil(t, err)
+	assert.Empty(t, xor.Polygons())
+}
+
+func TestGetOverlay_Holes(t *testing.T) {
+
+	a := NewMultiPolygon(NewPolygon(getSquare(0, 0, 3), getSquare(1, 1, 1)))
+	b := NewMultiPolygon(NewPolygon(getSquare(1, 1, 1)))
+	whole := NewPolygon(getSquare(0, 0, 3))
+
+	union, err := GetUnion(a, b)
+	assert.Nil(t, err)
+	assert.Len(t, union.Polygons(), 1)
+	assert.Empty(t, union.Polygons()[0].Holes())
+	assert.InDelta(t, whole.Area(), union.Area(), 1e-6)
+
+	// Cutting a hole out of a polygon.
+	difference, err := GetDifference(NewMultiPolygon(whole), b)
+	assert.Nil(t, err)
+	assert.Len(t, difference.Polygons(), 1)
+	assert.Len(t, difference.Polygons()[0].Holes(), 1)
+	assert.InDelta(t, a.Area(), difference.Area(), 1e-6)
+	assert.False(t, difference.Contains(NewPoint(1.5, 1.5)))
+	assert.True(t, difference.Contains(NewPoint(0.5, 0.5)))
+
+	// A square crossing the hole.
+	c := NewMultiPolygon(NewPolygon(getSquare(0.5, 0.5, 1)))
+
+	intersection, err := GetIntersection(a, c)
+	assert.Nil(t, err)
+	assert.Len(t, intersection.Polygons(), 1)
+	assert.False(t, intersection.Contains(NewPoint(1.25, 1.25)))
+	assert.True(t, intersection.Contains(NewPoint(0.75, 0.75)))
+	assert.Empty(t, ValidatePolygon(intersection.Polygons()[0]))
+
+	empty, err := GetUnion(NewMultiPolygon(), NewMultiPolygon())
+	assert.Nil(t, err)
+	assert.Empty(t, empty.Polygons())
+
+	union, err = GetUnion(c, NewMultiPolygon())
+	assert.Nil(t, err)
+	assert.InDelta(t, c.Area(), union.Area(), 1e-6)
+}
+
+func TestGetOverlay_Antimeridian(t *testing.T) {
+
+	box := NewBoundaryPolygon(NewBoundary(NewPoint(-1, 179), NewPoint(1, -179)))
+	square := NewPolygon(getSquare(0, 179.5, 1))
+
+	intersection, err := GetIntersection(NewMultiPolygon(box), NewMultiPolygon(square))
+	assert.Nil(t, err)
+	assert.Len(t, intersection.Polygons(), 1)
+	assert.True(t, intersection.Contains(NewPoint(0.5, 180)))
+	assert.True(t, intersection.Contains(NewPoint(0.5, -179.75)))
+	assert.False(t, intersection.Contains(NewPoint(-0.5, 180)))
+	// Only the bulge of the northern side of the square above the parallel of the boundary is left out.
+	assert.InDelta(t, square.Area(), intersection.Area(), 1)
+	assert.True(t, intersection.Area() < square.Area())
+}
+
+func TestGetOverlay_NotInHemisphere(t *testing.T) {
+
+	a := NewMultiPolygon(NewPolygon(getSquare(0, 0, 1)))
+	b := NewMultiPolygon(NewPolygon(getSquare(0, 179, 1)))
+
+	m, err := GetUnion(a, b)
+	assert.Nil(t, m)
+	assert.Equal(t, ErrNotInHemisphere, err)
+}
+
+func TestNewBoundaryPolygon(t *testing.T) {
+
+	assert.Nil(t, NewBoundaryPolygon(nil))
+	assert.Nil(t, NewBoundaryPolygon(NewBoundary(NewPoint(-90, 0), NewPoint(90, 0))))
+
+	p := NewBoundaryPolygon(NewBoundary(NewPoint(10, 20), NewPoint(12, 25)))
+	assert.True(t, p.Contains(NewPoint(11, 22)))
+	assert.False(t, p.Contains(NewPoint(13, 22)))
+	// The northern side follows the parallel rather than the great circle bulging towards the pole.
+	assert.False(t, p.Contains(NewPoint(12.001, 22.5)))
+	assert.InDelta(t, 2*5*111.2*111.2*math.Cos(11*math.Pi/180), p.Area(), 50)
+
+	p = NewBoundaryPolygon(NewBoundary(NewPoint(80, 0), NewPoint(90, 0)))
+	assert.True(t, p.Contains(NewPoint(90, 0)))
+	assert.True(t, p.Contains(NewPoint(85, -170)))
+	assert.False(t, p.Contains(NewPoint(79, 100)))
+
+	p = NewBoundaryPolygon(NewBoundary(NewPoint(-90, 0), NewPoint(-80, 0)))
+	assert.True(t, p.Contains(NewPoint(-90, 0)))
+	assert.False(t, p.Contains(NewPoint(-79, 100)))
+
+	// A pole touching boundary that doesn't go around earth has the pole as a vertex.
+	p = NewBoundaryPolygon(&boundary{lower: &point{latitude: 80, longitude: 170}, upper: &point{latitude: 90, longitude: -170}})
+	assert.True(t, p.Contains(NewPoint(85, 180)))
+	assert.True(t, p.Contains(NewPoint(89.9, 175)))
+	assert.False(t, p.Contains(NewPoint(85, 160)))
+	assert.False(t, p.Contains(NewPoint(85, 0)))
+	assert.Empty(t, ValidatePolygon(p))
+
+	p = NewBoundaryPolygon(&boundary{lower: &point{latitude: -90, longitude: -10}, upper: &point{latitude: -80, longitude: 10}})
+	assert.True(t, p.Contains(NewPoint(-85, 0)))
+	assert.False(t, p.Contains(NewPoint(-85, 20)))
+	assert.Empty(t, ValidatePolygon(p))
+}
+
+func TestNewBoundaryPolygon_Hemisphere(t *testing.T) {
+
+	// The cap north of 30S covers more than a hemisphere, so no ring can bound it.
+	assert.Nil(t, NewBoundaryPolygon(NewBoundary(NewPoint(-30, 0), NewPoint(90, 0))))
+	assert.Nil(t, NewBoundaryPolygon(NewBoundary(NewPoint(-90, 0), NewPoint(30, 0))))
+	assert.Nil(t, NewBoundaryPolygon(NewBoundary(NewPoint(-60, -170), NewPoint(60, 170))))
+
+	// The half of the same cap east of the prime meridian covers less than a hemisphere.
+	p := NewBoundaryPolygon(&boundary{lower: &point{latitude: -30, longitude: 0}, upper: &point{latitude: 90, longitude: 180}})
+	assert.True(t, p.Contains(NewPoint(0, 90)))
+	assert.True(t, p.Contains(NewPoint(-29, 1)))
+	assert.False(t, p.Contains(NewPoint(0, -90)))
+	assert.False(t, p.Contains(NewPoint(-60, 90)))
+
+	p = NewBoundaryPolygon(NewBoundary(NewPoint(10, 0), NewPoint(90, 0)))
+	assert.True(t, p.Contains(NewPoint(20, -170)))
+	assert.False(t, p.Contains(NewPoint(0, 0)))
+}
@@ -0,0 +1,394 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Polygon is an area on the surface of earth bounded by an outer ring (the shell), excluding the areas bounded by
+// its inner rings (the holes).
+// Each ring is a closed sequence of geo-location points connected by great circle arcs, where the first point
+// is expected to be repeated at the end of the ring.
+// A ring divides the surface of earth into two areas, the area bounded by a ring is always the smaller one of
+// them, regardless of the ring orientation, so rings can go around the antimeridian or around a pole as well.
+type Polygon interface {
+	// Shell returns the outer ring of the polygon.
+	Shell() []Point
+	// Holes returns the inner rings of the polygon.
+	Holes() [][]Point
+	// Contains reports whether the given point is inside the polygon.
+	Contains(point Point) bool
+	// Boundary returns the smallest boundary containing the polygon.
+	Boundary() Boundary
+	// Area returns the area of the polygon in square kilometers on the sphere, which is the area bounded by its
+	// shell minus the areas bounded by its holes.
+	Area() float64
+	// EllipsoidArea returns the area of the polygon in square kilometers on the WGS84 ellipsoid, where the ring
+	// edges are geodesics, which is the area bounded by its shell minus the areas bounded by its holes.
+	EllipsoidArea() float64
+	// Perimeter returns the total length in kilometers of the polygon rings on the sphere.
+	Perimeter() float64
+	// EllipsoidPerimeter returns the total length in kilometers of the polygon rings on the WGS84 ellipsoid.
+	EllipsoidPerimeter() float64
+}
+
+// MultiPolygon is a collection of polygons treated as a single area.
+type MultiPolygon interface {
+	// Polygons returns the polygons of the collection.
+	Polygons() []Polygon
+	// Contains reports whether the given point is inside any of the polygons.
+	Contains(point Point) bool
+	// Boundary returns the smallest boundary containing all the polygons.
+	Boundary() Boundary
+	// Area returns the sum of the areas of the polygons in square kilometers on the sphere.
+	Area() float64
+	// EllipsoidArea returns the sum of the areas of the polygons in square kilometers on the WGS84 ellipsoid.
+	EllipsoidArea() float64
+	// Perimeter returns the sum of the perimeters of the polygons in kilometers on the sphere.
+	Perimeter() float64
+	// EllipsoidPerimeter returns the sum of the perimeters of the polygons in kilometers on the WGS84 ellipsoid.
+	EllipsoidPerimeter() float64
+}
+
+// ring is a closed sequence of unit vectors prepared for containment tests.
+type ring struct {
+	// vectors holds the ring vertices, without repeating the first one at the end.
+	vectors []vector
+	// reference is a vector known to be on the left side of the ring.
+	reference vector
+	// leftInterior is whether the area on the left side of the ring is the smaller one.
+	leftInterior bool
+}
+
+func newRing(points []Point) *ring {
+
+	r := &ring{vectors: getRingVectors(points)}
+
+	if len(r.vectors) < 3 {
+		r.vectors = nil
+		return r
+	}
+
+	// The reference is placed just to the left of the middle of the first edge.
+	a, b := r.vectors[0], r.vectors[1]
+	r.reference = interpolate(a, b, 0.5).add(a.cross(b).normalize().scale(1e-10)).normalize()
+
+	// Following Gauss-Bonnet theorem, the area on the left of a ring equals 2π minus the sum of its turning angles,
+	// so it's the smaller area if the turning angles add up to a positive value.
+	r.leftInterior = r.turning() > 0
+
+	return r
+}
+
+// turning returns the sum of the turning angles at the ring vertices, where turning left is positive.
+func (r *ring) turning() float64 {
+
+	sum := 0.0
+	n := len(r.vectors)
+
+	for i := range r.vectors {
+		a, b, c := r.vectors[(i+n-1)%n], r.vectors[i], r.vectors[(i+1)%n]
+		n1, n2 := a.cross(b), b.cross(c)
+		sum += math.Atan2(n1.cross(n2).dot(b), n1.dot(n2))
+	}
+
+	return sum
+}
+
+// crossings returns the number of the ring edges crossed by the great circle arc going from a to b.
+func (r *ring) crossings(a, b vector) int {
+
+	count := 0
+	n := len(r.vectors)
+
+	for i := range r.vectors {
+		if arcsCross(a, b, r.vectors[i], r.vectors[(i+1)%n]) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// contains reports whether the given vector is inside the area bounded by the ring.
+func (r *ring) contains(v vector) bool {
+
+	if len(r.vectors) == 0 {
+		return false
+	}
+
+	var count int
+
+	if r.reference.dot(v) < -0.5 {
+		// Going through a point in the middle, since the arc between nearly antipodal vectors is not well defined.
+		middle := r.reference.cross(v)
+
+		if middle.norm() < epsilon {
+			middle = r.reference.perpendicular()
+		}
+
+		middle = middle.normalize()
+
+		count = r.crossings(r.reference, middle) + r.crossings(middle, v)
+	} else {
+		count = r.crossings(r.reference, v)
+	}
+
+	// Each crossing switches sides, starting on the left side of the ring where the reference is.
+	return (count%2 == 0) == r.leftInterior
+}
+
+type polygon struct {
+	shell      []Point
+	holes      [][]Point
+	shellRing  *ring
+	holesRings []*ring
+}
+
+func (p *polygon) String() string {
+	return fmt.Sprintf("%v", append([][]Point{p.Shell()}, p.Holes()...))
+}
+
+func (p *polygon) Shell() []Point {
+	if p != nil {
+		return p.shell
+	}
+	return nil
+}
+
+func (p *polygon) Holes() [][]Point {
+	if p != nil {
+		return p.holes
+	}
+	return nil
+}
+
+func (p *polygon) Contains(point Point) bool {
+
+	if p == nil || point == nil {
+		return false
+	}
+
+	v := toVector(point)
+
+	if !p.shellRing.contains(v) {
+		return false
+	}
+
+	for _, h := range p.holesRings {
+		if h.contains(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// extremes returns the points needed to find the boundary of the polygon, including the poles it contains.
+func (p *polygon) extremes() []Point {
+
+	if p == nil {
+		return nil
+	}
+
+	all := getLineExtremes(closeRing(p.shell))
+
+	for _, pole := range []Point{NewPoint(NorthPoleLat, 0), NewPoint(SouthPoleLat, 0)} {
+		if p.shellRing.contains(toVector(pole)) {
+			all = append(all, pole)
+		}
+	}
+
+	return all
+}
+
+func (p *polygon) Boundary() Boundary {
+	return getPointsBoundary(p.extremes())
+}
+
+// rings returns the shell followed by the holes of the polygon.
+func (p *polygon) rings() [][]Point {
+	if p == nil {
+		return nil
+	}
+	return append([][]Point{p.shell}, p.holes...)
+}
+
+// measure returns the area of the polygon using the given signed ring area function.
+func (p *polygon) measure(area func([]Point) float64) float64 {
+
+	total := 0.0
+
+	for i, r := range p.rings() {
+		if i == 0 {
+			total += math.Abs(area(r))
+		} else {
+			total -= math.Abs(area(r))
+		}
+	}
+
+	return total
+}
+
+func (p *polygon) Area() float64 {
+	return p.measure(GetRingArea)
+}
+
+func (p *polygon) EllipsoidArea() float64 {
+	return p.measure(GetRingEllipsoidArea)
+}
+
+// length returns the total length of the polygon rings using the given distance function.
+func (p *polygon) length(distance func(Point, Point) float64) float64 {
+
+	total := 0.0
+
+	for _, r := range p.rings() {
+		r = closeRing(r)
+
+		for i := 1; i < len(r); i++ {
+			if r[i-1] != nil && r[i] != nil {
+				total += distance(r[i-1], r[i])
+			}
+		}
+	}
+
+	return total
+}
+
+func (p *polygon) Perimeter() float64 {
+	return p.length(func(p1, p2 Point) float64 {
+		return angleToKM(toVector(p1).angle(toVector(p2)))
+	})
+}
+
+func (p *polygon) EllipsoidPerimeter() float64 {
+	return p.length(GetEllipsoidDistance)
+}
+
+// closeRing returns the given ring with its first point repeated at the end, if it's not already there.
+func closeRing(points []Point) []Point {
+
+	if len(points) == 0 || points[0] == nil || points[len(points)-1] == nil {
+		return points
+	}
+
+	first, last := points[0], points[len(points)-1]
+
+	if first.Latitude() == last.Latitude() && first.Longitude() == last.Longitude() {
+		return points
+	}
+
+	return append(append([]Point{}, points...), first)
+}
+
+// NewPolygon creates a new polygon having the given outer ring and inner rings.
+func NewPolygon(shell []Point, holes ...[]Point) Polygon {
+
+	p := &polygon{shell: shell, holes: holes, shellRing: newRing(shell)}
+
+	for _, h := range holes {
+		p.holesRings = append(p.holesRings, newRing(h))
+	}
+
+	return p
+}
+
+type multiPolygon struct {
+	polygons []Polygon
+}
+
+func (m *multiPolygon) String() string {
+	return fmt.Sprintf("%v", m.Polygons())
+}
+
+func (m *multiPolygon) Polygons() []Polygon {
+	if m != nil {
+		return m.polygons
+	}
+	return nil
+}
+
+func (m *multiPolygon) Contains(point Point) bool {
+
+	for _, p := range m.Polygons() {
+		if p.Contains(point) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *multiPolygon) Boundary() Boundary {
+
+	all := []Point{}
+
+	for _, p := range m.Polygons() {
+		if pp, ok := p.(*polygon); ok {
+			all = append(all, pp.extremes()...)
+		} else if b := p.Boundary(); b != nil {
+			all = append(all, b.Lower(), b.Upper())
+		}
+	}
+
+	return getPointsBoundary(all)
+}
+
+// sum returns the sum of the given measure of all the polygons.
+func (m *multiPolygon) sum(measure func(Polygon) float64) float64 {
+
+	total := 0.0
+
+	for _, p := range m.Polygons() {
+		total += measure(p)
+	}
+
+	return total
+}
+
+func (m *multiPolygon) Area() float64 {
+	return m.sum(Polygon.Area)
+}
+
+func (m *multiPolygon) EllipsoidArea() float64 {
+	return m.sum(Polygon.EllipsoidArea)
+}
+
+func (m *multiPolygon) Perimeter() float64 {
+	return m.sum(Polygon.Perimeter)
+}
+
+func (m *multiPolygon) EllipsoidPerimeter() float64 {
+	return m.sum(Polygon.EllipsoidPerimeter)
+}
+
+// NewMultiPolygon creates a new collection of the given polygons, nil polygons are left out.
+func NewMultiPolygon(polygons ...Polygon) MultiPolygon {
+
+	m := &multiPolygon{}
+
+	for _, p := range polygons {
+		if p != nil {
+			m.polygons = append(m.polygons, p)
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getSquare(lat, lng, size float64) []Point {
+	return []Point{
+		NewPoint(lat, lng), NewPoint(lat, lng+size), NewPoint(lat+size, lng+size), NewPoint(lat+size, lng), NewPoint(lat, lng),
+	}
+}
+
+func reverseRing(points []Point) []Point {
+	r := make([]Point, len(points))
+	for i, p := range points {
+		r[len(r)-1-i] = p
+	}
+	return r
+}
+
+func TestPolygon_Nil(t *testing.T) {
+
+	var p *polygon
+	var pl Polygon = p
+
+	assert.Nil(t, pl.Shell())
+	assert.Nil(t, pl.Holes())
+	assert.False(t, pl.Contains(NewPoint(0, 0)))
+	assert.Nil(t, pl.Boundary())
+
+	var m *multiPolygon
+	var mp MultiPolygon = m
+
+	assert.Nil(t, mp.Polygons())
+	assert.False(t, mp.Contains(NewPoint(0, 0)))
+	assert.Nil(t, mp.Boundary())
+
+	assert.False(t, NewPolygon(nil).Contains(NewPoint(0, 0)))
+	assert.False(t, NewPolygon(getSquare(0, 0, 1)[:2]).Contains(NewPoint(0, 0)))
+	assert.False(t, NewPolygon(getSquare(0, 0, 1)).Contains(nil))
+}
+
+func TestPolygon_Contains(t *testing.T) {
+
+	shell := getSquare(0, 0, 10)
+	hole := getSquare(4, 4, 2)
+
+	for _, p := range []Polygon{NewPolygon(shell, hole), NewPolygon(reverseRing(shell), reverseRing(hole))} {
+		assert.True(t, p.Contains(NewPoint(1, 1)))
+		assert.True(t, p.Contains(NewPoint(9, 9)))
+		assert.False(t, p.Contains(NewPoint(5, 5)))
+		assert.False(t, p.Contains(NewPoint(-1, 5)))
+		assert.False(t, p.Contains(NewPoint(5, 11)))
+		assert.False(t, p.Contains(NewPoint(-5, -175)))
+	}
+
+	// The shell doesn't have to be closed.
+	p := NewPolygon(shell[:4])
+	assert.True(t, p.Contains(NewPoint(5, 5)))
+	assert.Equal(t, shell[:4], p.Shell())
+	assert.Equal(t, "[[(0, 0) (0, 10) (10, 10) (10, 0)]]", p.(*polygon).String())
+
+	// Vertices lying exactly on the way from the reference to the point don't change the result.
+	p = NewPolygon([]Point{NewPoint(0, 0), NewPoint(0, 2), NewPoint(1, 3), NewPoint(2, 2), NewPoint(2, 0)})
+	for lat := 0.25; lat < 2; lat += 0.25 {
+		for lng := 0.25; lng < 2; lng += 0.25 {
+			assert.True(t, p.Contains(NewPoint(lat, lng)))
+		}
+	}
+}
+
+func TestPolygon_Antimeridian(t *testing.T) {
+
+	p := NewPolygon([]Point{NewPoint(-10, 170), NewPoint(-10, -170), NewPoint(10, -170), NewPoint(10, 170), NewPoint(-10, 170)})
+
+	assert.True(t, p.Contains(NewPoint(0, 180)))
+	assert.True(t, p.Contains(NewPoint(5, 175)))
+	assert.True(t, p.Contains(NewPoint(-5, -175)))
+	assert.False(t, p.Contains(NewPoint(0, 0)))
+	assert.False(t, p.Contains(NewPoint(0, 160)))
+
+	b := p.Boundary()
+	assert.InDelta(t, 170, b.Lower().Longitude(), 0)
+	assert.InDelta(t, -170, b.Upper().Longitude(), 0)
+	assert.True(t, b.Upper().Latitude() > 10)
+	assert.True(t, b.Lower().Latitude() < -10)
+}
+
+func TestPolygon_Pole(t *testing.T) {
+
+	shell := []Point{}
+	for lng := -180.0; lng < 180; lng += 30 {
+		shell = append(shell, NewPoint(80, lng))
+	}
+
+	for _, p := range []Polygon{NewPolygon(shell), NewPolygon(reverseRing(shell))} {
+		assert.True(t, p.Contains(NewPoint(90, 0)))
+		assert.True(t, p.Contains(NewPoint(85, 123)))
+		assert.False(t, p.Contains(NewPoint(70, 0)))
+		assert.False(t, p.Contains(NewPoint(-90, 0)))
+		assert.False(t, p.Contains(NewPoint(-85, 0)))
+
+		b := p.Boundary()
+		assert.InDelta(t, 90, b.Upper().Latitude(), 0)
+		assert.InDelta(t, 80, b.Lower().Latitude(), 0)
+	}
+}
+
+func TestPolygon_Random(t *testing.T) {
+
+	// A star shaped polygon around a random center, compared with a containment test done in the plane of
+	// the gnomonic projection, where great circle arcs are straight lines.
+	for i := 0; i < 20; i++ {
+		center := NewPoint(rand.Float64()*180-90, rand.Float64()*360-180)
+		g := newGnomonic(toVector(center))
+		shell := []Point{}
+		xs, ys := []float64{}, []float64{}
+
+		for a := 0; a < 12; a++ {
+			r := 0.05 + rand.Float64()*0.3
+			x, y := r*cosDeg(float64(a)*30), r*sinDeg(float64(a)*30)
+			xs, ys = append(xs, x), append(ys, y)
+			shell = append(shell, g.unproject(x, y).toPoint())
+		}
+
+		p := NewPolygon(shell)
+
+		for j := 0; j < 200; j++ {
+			x, y := rand.Float64()*0.8-0.4, rand.Float64()*0.8-0.4
+			v := g.unproject(x, y)
+			assert.Equal(t, planeContains(xs, ys, x, y), p.Contains(v.toPoint()))
+		}
+	}
+}
+
+func planeContains(xs, ys []float64, x, y float64) bool {
+	inside := false
+	for i, j := 0, len(xs)-1; i < len(xs); j, i = i, i+1 {
+		if (ys[i] > y) != (ys[j] > y) && x < (xs[j]-xs[i])*(y-ys[i])/(ys[j]-ys[i])+xs[i] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func TestMultiPolygon(t *testing.T) {
+
+	m := NewMultiPolygon(NewPolygon(getSquare(0, 0, 1)), nil, NewPolygon(getSquare(0, 178, 1)))
+
+	assert.Len(t, m.Polygons(), 2)
+	assert.True(t, m.Contains(NewPoint(0.5, 0.5)))
+	assert.True(t, m.Contains(NewPoint(0.5, 178.5)))
+	assert.False(t, m.Contains(NewPoint(0.5, 90)))
+
+	b := m.Boundary()
+	assert.InDelta(t, 0, b.Lower().Longitude(), 0)
+	assert.InDelta(t, 179, b.Upper().Longitude(), 0)
+	assert.NotEmpty(t, m.(*multiPolygon).String())
+}
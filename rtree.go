@@ -0,0 +1,692 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+const (
+	// rtreeMaxEntries is the maximum number of entries of an R-tree node.
+	rtreeMaxEntries = 16
+	// rtreeMinEntries is the minimum number of entries of an R-tree node other than the root.
+	rtreeMinEntries = 6
+	// distanceMargin is the distance in kilometers taken off the distance lower bounds,
+	// so they never exceed the distances measured by GetDistance because of rounding.
+	distanceMargin = 1e-6
+)
+
+// RTreeEntry is a value stored in an R-tree along with the boundary it's keyed by.
+type RTreeEntry[T any] struct {
+	// Boundary is the boundary the value is keyed by.
+	Boundary Boundary
+	// Value is the value stored.
+	Value T
+}
+
+// RTree is an in-memory spatial index of values keyed by boundaries, where a geo-location point is keyed by the
+// boundary having it as both its lower and upper bounds.
+// A boundary crossing the antimeridian is split into two rectangles, one on each side of it, and a boundary
+// touching a pole covers all the longitudes, so they are found by any search around them.
+type RTree[T any] interface {
+	// Len returns the number of values in the tree.
+	Len() int
+	// Insert adds the given value keyed by the given boundary, nil boundaries are ignored.
+	Insert(b Boundary, value T)
+	// Delete removes the first value keyed by a boundary having the same bounds as the given one, for which
+	// the given function returns true, reporting whether a value was removed.
+	Delete(b Boundary, match func(value T) bool) bool
+	// Search calls the given function with each value keyed by a boundary intersecting the given boundary,
+	// until it returns false.
+	Search(b Boundary, fn func(b Boundary, value T) bool)
+	// Nearest calls the given function with each value in increasing order of the distance in kilometers,
+	// as measured by GetDistance, between the given point and the nearest point of the boundary it's keyed by,
+	// until it returns false.
+	Nearest(p Point, fn func(b Boundary, value T, distanceInKM float64) bool)
+}
+
+// rect is a latlng rectangle not crossing the antimeridian.
+type rect struct {
+	minLat, maxLat, minLng, maxLng float64
+}
+
+func (r rect) union(o rect) rect {
+	return rect{
+		minLat: math.Min(r.minLat, o.minLat),
+		maxLat: math.Max(r.maxLat, o.maxLat),
+		minLng: math.Min(r.minLng, o.minLng),
+		maxLng: math.Max(r.maxLng, o.maxLng),
+	}
+}
+
+func (r rect) area() float64 {
+	return (r.maxLat - r.minLat) * (r.maxLng - r.minLng)
+}
+
+func (r rect) margin() float64 {
+	return (r.maxLat - r.minLat) + (r.maxLng - r.minLng)
+}
+
+func (r rect) intersects(o rect) bool {
+	return r.minLat <= o.maxLat && o.minLat <= r.maxLat && r.minLng <= o.maxLng && o.minLng <= r.maxLng
+}
+
+func (r rect) contains(o rect) bool {
+	return r.minLat <= o.minLat && o.maxLat <= r.maxLat && r.minLng <= o.minLng && o.maxLng <= r.maxLng
+}
+
+func (r rect) overlap(o rect) float64 {
+	lat := math.Min(r.maxLat, o.maxLat) - math.Max(r.minLat, o.minLat)
+	lng := math.Min(r.maxLng, o.maxLng) - math.Max(r.minLng, o.minLng)
+	return math.Max(0, lat) * math.Max(0, lng)
+}
+
+// lowerDistance returns a lower bound of the distances in kilometers, as measured by GetDistance, between the
+// given point and all the points of the rectangle.
+// Each term of the haversine formula used by GetDistance is bounded on its own, so the bound holds with the
+// approximate degree to radian conversion it uses as well.
+func (r rect) lowerDistance(p Point) float64 {
+
+	lat, lng := p.Latitude(), p.Longitude()
+
+	haversine := func(degrees float64) float64 {
+		return math.Pow(math.Sin(degrees*DegToRad/2), 2)
+	}
+
+	clamp := func(x, min, max float64) float64 {
+		return math.Max(min, math.Min(max, x))
+	}
+
+	// The haversine of the latitude difference rises then falls, so its least value is at one of the extremes.
+	latTerm := math.Min(
+		haversine(math.Abs(lat-clamp(lat, r.minLat, r.maxLat))),
+		haversine(math.Max(math.Abs(lat-r.minLat), math.Abs(lat-r.maxLat))))
+
+	lngDiff := 0.0
+
+	if lng < r.minLng || lng > r.maxLng {
+		lngDiff = math.Min(
+			math.Abs(math.Remainder(lng-r.minLng, TotalLongitude)),
+			math.Abs(math.Remainder(lng-r.maxLng, TotalLongitude)))
+	}
+
+	// The longitude term is the product of the cosines of both latitudes and the haversine of the longitude
+	// difference, which may be negative very close to the poles, so all the combinations of their extremes count.
+	cosLat := math.Cos(lat * DegToRad)
+	lngTerm := math.Inf(1)
+
+	for _, c := range []float64{
+		math.Cos(r.minLat * DegToRad), math.Cos(r.maxLat * DegToRad), math.Cos(clamp(0, r.minLat, r.maxLat) * DegToRad),
+	} {
+		for _, h := range []float64{math.Min(haversine(lngDiff), haversine(HalfLongitude)), 1} {
+			lngTerm = math.Min(lngTerm, cosLat*c*h)
+		}
+	}
+
+	a := math.Max(0, math.Min(1, latTerm+lngTerm))
+
+	return math.Max(0, EarthRadiusInKM*2*math.Asin(math.Sqrt(a))-distanceMargin)
+}
+
+// getBoundaryRects returns the rectangles covering the given boundary, which are two if it crosses the antimeridian.
+func getBoundaryRects(b Boundary) []rect {
+
+	minLat, maxLat := b.Lower().Latitude(), b.Upper().Latitude()
+	minLng, maxLng := getBoundaryLngRange(b)
+
+	if maxLng-minLng >= TotalLongitude {
+		return []rect{{minLat, maxLat, -HalfLongitude, HalfLongitude}}
+	}
+
+	if maxLng > HalfLongitude {
+		return []rect{{minLat, maxLat, minLng, HalfLongitude}, {minLat, maxLat, -HalfLongitude, maxLng - TotalLongitude}}
+	}
+
+	return []rect{{minLat, maxLat, minLng, maxLng}}
+}
+
+// getBoundaryNearest returns the point of the given boundary nearest to the given point.
+func getBoundaryNearest(b Boundary, p Point) Point {
+
+	if boundaryContains(b, p) {
+		return p
+	}
+
+	minLat, maxLat := b.Lower().Latitude(), b.Upper().Latitude()
+	minLng, maxLng := getBoundaryLngRange(b)
+	lat := math.Max(minLat, math.Min(maxLat, p.Latitude()))
+	lng := p.Longitude()
+
+	if lng < minLng {
+		lng += TotalLongitude
+	}
+
+	// Within the longitude range the nearest point is on the same meridian, and so it is from a pole.
+	if lng <= maxLng {
+		return NewPoint(lat, lng)
+	}
+
+	if math.Abs(p.Latitude()) == NorthPoleLat {
+		return NewPoint(lat, minLng)
+	}
+
+	// Otherwise it's on one of the meridian sides, where it's either the point of the meridian closest
+	// to the given point, or one of the corners.
+	var nearest Point
+	best := math.Inf(1)
+
+	for _, side := range []float64{minLng, maxLng} {
+		candidates := []Point{NewPoint(minLat, side), NewPoint(maxLat, side)}
+		phi := p.Latitude() * radians
+
+		if x := math.Cos(phi) * math.Cos((p.Longitude()-side)*radians); x > 0 {
+			closest := math.Atan2(math.Sin(phi), x) / radians
+			candidates = append(candidates, NewPoint(math.Max(minLat, math.Min(maxLat, closest)), side))
+		}
+
+		for _, c := range candidates {
+			if d := GetDistance(p, c); d < best {
+				nearest, best = c, d
+			}
+		}
+	}
+
+	return nearest
+}
+
+// getBoundaryDistance returns the distance in kilometers, as measured by GetDistance, between the given point and
+// the nearest point of the given boundary, which is zero if the boundary contains it.
+func getBoundaryDistance(b Boundary, p Point) float64 {
+
+	if boundaryContains(b, p) {
+		return 0
+	}
+
+	return GetDistance(p, getBoundaryNearest(b, p))
+}
+
+// sameBoundary reports whether the two boundaries have the same bounds.
+func sameBoundary(a, b Boundary) bool {
+	return a.Lower().Latitude() == b.Lower().Latitude() && a.Lower().Longitude() == b.Lower().Longitude() &&
+		a.Upper().Latitude() == b.Upper().Latitude() && a.Upper().Longitude() == b.Upper().Longitude()
+}
+
+// rtreeItem is a value stored in an R-tree, which is referred to by a leaf entry for each of its rectangles.
+type rtreeItem[T any] struct {
+	boundary Boundary
+	value    T
+	rects    []rect
+}
+
+// rtreeEntry is an entry of an R-tree node, referring to a child node, or to an item in a leaf node.
+type rtreeEntry[T any] struct {
+	rect  rect
+	child *rtreeNode[T]
+	item  *rtreeItem[T]
+}
+
+type rtreeNode[T any] struct {
+	leaf    bool
+	entries []rtreeEntry[T]
+}
+
+func (n *rtreeNode[T]) bounds() rect {
+
+	r := n.entries[0].rect
+
+	for _, e := range n.entries[1:] {
+		r = r.union(e.rect)
+	}
+
+	return r
+}
+
+// items appends the leaf entries under the node to the given ones.
+func (n *rtreeNode[T]) items(entries []rtreeEntry[T]) []rtreeEntry[T] {
+
+	if n.leaf {
+		return append(entries, n.entries...)
+	}
+
+	for _, e := range n.entries {
+		entries = e.child.items(entries)
+	}
+
+	return entries
+}
+
+// split moves part of the node entries into a new sibling node using the R*-tree split, which picks the axis
+// having the least margin over all its distributions, then the distribution on it having the least overlap.
+func (n *rtreeNode[T]) split() *rtreeNode[T] {
+
+	count := len(n.entries)
+	sortings := [][]rtreeEntry[T]{}
+
+	for _, key := range []func(r rect) (float64, float64){
+		func(r rect) (float64, float64) { return r.minLat, r.maxLat },
+		func(r rect) (float64, float64) { return r.maxLat, r.minLat },
+		func(r rect) (float64, float64) { return r.minLng, r.maxLng },
+		func(r rect) (float64, float64) { return r.maxLng, r.minLng },
+	} {
+		sorted := append([]rtreeEntry[T]{}, n.entries...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			a1, a2 := key(sorted[i].rect)
+			b1, b2 := key(sorted[j].rect)
+			return a1 < b1 || a1 == b1 && a2 < b2
+		})
+		sortings = append(sortings, sorted)
+	}
+
+	groups := func(sorted []rtreeEntry[T], k int) (rect, rect) {
+		first := (&rtreeNode[T]{entries: sorted[:k]}).bounds()
+		second := (&rtreeNode[T]{entries: sorted[k:]}).bounds()
+		return first, second
+	}
+
+	// Each axis has two sortings, by the lower then by the upper values.
+	axis, bestMargin := 0, math.Inf(1)
+
+	for a := 0; a < 2; a++ {
+		margin := 0.0
+
+		for _, sorted := range sortings[2*a : 2*a+2] {
+			for k := rtreeMinEntries; k <= count-rtreeMinEntries; k++ {
+				first, second := groups(sorted, k)
+				margin += first.margin() + second.margin()
+			}
+		}
+
+		if margin < bestMargin {
+			axis, bestMargin = a, margin
+		}
+	}
+
+	var best []rtreeEntry[T]
+	bestK, bestOverlap, bestArea := 0, math.Inf(1), math.Inf(1)
+
+	for _, sorted := range sortings[2*axis : 2*axis+2] {
+		for k := rtreeMinEntries; k <= count-rtreeMinEntries; k++ {
+			first, second := groups(sorted, k)
+			overlap, area := first.overlap(second), first.area()+second.area()
+
+			if overlap < bestOverlap || overlap == bestOverlap && area < bestArea {
+				best, bestK, bestOverlap, bestArea = sorted, k, overlap, area
+			}
+		}
+	}
+
+	n.entries = append([]rtreeEntry[T]{}, best[:bestK]...)
+
+	return &rtreeNode[T]{leaf: n.leaf, entries: append([]rtreeEntry[T]{}, best[bestK:]...)}
+}
+
+// chooseSubtree returns the index of the entry needing the least enlargement of its rectangle to include the given
+// one, breaking the ties by the least area.
+func (n *rtreeNode[T]) chooseSubtree(r rect) int {
+
+	best, bestEnlargement, bestArea := 0, math.Inf(1), math.Inf(1)
+
+	for i, e := range n.entries {
+		area := e.rect.area()
+		enlargement := e.rect.union(r).area() - area
+
+		if enlargement < bestEnlargement || enlargement == bestEnlargement && area < bestArea {
+			best, bestEnlargement, bestArea = i, enlargement, area
+		}
+	}
+
+	return best
+}
+
+type rtree[T any] struct {
+	root  *rtreeNode[T]
+	count int
+}
+
+func (t *rtree[T]) Len() int {
+	return t.count
+}
+
+// insertInto adds the given leaf entry under the given node, returning the new sibling of the node if it was split.
+func (t *rtree[T]) insertInto(n *rtreeNode[T], e rtreeEntry[T]) *rtreeNode[T] {
+
+	if n.leaf {
+		n.entries = append(n.entries, e)
+	} else {
+		i := n.chooseSubtree(e.rect)
+		child := n.entries[i].child
+
+		sibling := t.insertInto(child, e)
+		n.entries[i].rect = child.bounds()
+
+		if sibling != nil {
+			n.entries = append(n.entries, rtreeEntry[T]{rect: sibling.bounds(), child: sibling})
+		}
+	}
+
+	if len(n.entries) > rtreeMaxEntries {
+		return n.split()
+	}
+
+	return nil
+}
+
+func (t *rtree[T]) insert(e rtreeEntry[T]) {
+	if sibling := t.insertInto(t.root, e); sibling != nil {
+		t.root = &rtreeNode[T]{entries: []rtreeEntry[T]{
+			{rect: t.root.bounds(), child: t.root},
+			{rect: sibling.bounds(), child: sibling},
+		}}
+	}
+}
+
+func (t *rtree[T]) Insert(b Boundary, value T) {
+
+	if b == nil {
+		return
+	}
+
+	item := &rtreeItem[T]{boundary: b, value: value, rects: getBoundaryRects(b)}
+
+	for _, r := range item.rects {
+		t.insert(rtreeEntry[T]{rect: r, item: item})
+	}
+
+	t.count++
+}
+
+// remove removes the leaf entry of the given item having the given rectangle from under the given node, appending
+// the leaf entries under the nodes left with too few entries to the orphans, reporting whether it was found.
+func (t *rtree[T]) remove(n *rtreeNode[T], r rect, item *rtreeItem[T], orphans *[]rtreeEntry[T]) bool {
+
+	if n.leaf {
+		for i, e := range n.entries {
+			if e.item == item && e.rect == r {
+				n.entries = append(n.entries[:i], n.entries[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := range n.entries {
+		e := &n.entries[i]
+
+		if !e.rect.contains(r) || !t.remove(e.child, r, item, orphans) {
+			continue
+		}
+
+		if len(e.child.entries) < rtreeMinEntries {
+			*orphans = e.child.items(*orphans)
+			n.entries = append(n.entries[:i], n.entries[i+1:]...)
+		} else {
+			e.rect = e.child.bounds()
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func (t *rtree[T]) Delete(b Boundary, match func(value T) bool) bool {
+
+	if b == nil {
+		return false
+	}
+
+	var found *rtreeItem[T]
+
+	t.search(getBoundaryRects(b)[:1], func(item *rtreeItem[T]) bool {
+		if sameBoundary(item.boundary, b) && (match == nil || match(item.value)) {
+			found = item
+		}
+		return found == nil
+	})
+
+	if found == nil {
+		return false
+	}
+
+	orphans := []rtreeEntry[T]{}
+
+	for _, r := range found.rects {
+		t.remove(t.root, r, found, &orphans)
+	}
+
+	for !t.root.leaf && len(t.root.entries) < 2 {
+		if len(t.root.entries) == 0 {
+			t.root = &rtreeNode[T]{leaf: true}
+		} else {
+			t.root = t.root.entries[0].child
+		}
+	}
+
+	for _, e := range orphans {
+		// The other rectangle of an item split by the antimeridian may have been orphaned before it was removed.
+		if e.item != found {
+			t.insert(e)
+		}
+	}
+
+	t.count--
+
+	return true
+}
+
+// search calls the given function with each item having a rectangle intersecting any of the given rectangles,
+// once per item, until it returns false.
+func (t *rtree[T]) search(rects []rect, fn func(item *rtreeItem[T]) bool) {
+
+	seen := make(map[*rtreeItem[T]]bool)
+
+	var visit func(n *rtreeNode[T], r rect) bool
+
+	visit = func(n *rtreeNode[T], r rect) bool {
+		for _, e := range n.entries {
+			if !e.rect.intersects(r) {
+				continue
+			}
+
+			if !n.leaf {
+				if !visit(e.child, r) {
+					return false
+				}
+				continue
+			}
+
+			// Items split by the antimeridian, or found by both halves of a split search, are visited once.
+			if len(e.item.rects) > 1 || len(rects) > 1 {
+				if seen[e.item] {
+					continue
+				}
+				seen[e.item] = true
+			}
+
+			if !fn(e.item) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for _, r := range rects {
+		if !visit(t.root, r) {
+			return
+		}
+	}
+}
+
+func (t *rtree[T]) Search(b Boundary, fn func(b Boundary, value T) bool) {
+
+	if b == nil {
+		return
+	}
+
+	t.search(getBoundaryRects(b), func(item *rtreeItem[T]) bool {
+		return fn(item.boundary, item.value)
+	})
+}
+
+// rtreeCandidate is either a node or an item queued by the nearest neighbour search, along with its distance or
+// distance lower bound.
+type rtreeCandidate[T any] struct {
+	distance float64
+	node     *rtreeNode[T]
+	item     *rtreeItem[T]
+}
+
+// rtreeQueue is a min heap of the candidates of the nearest neighbour search by their distances, where the items
+// come before the nodes having the same distance.
+type rtreeQueue[T any] []rtreeCandidate[T]
+
+func (q rtreeQueue[T]) Len() int {
+	return len(q)
+}
+
+func (q rtreeQueue[T]) Less(i, j int) bool {
+	if q[i].distance != q[j].distance {
+		return q[i].distance < q[j].distance
+	}
+	return q[i].item != nil && q[j].item == nil
+}
+
+func (q rtreeQueue[T]) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *rtreeQueue[T]) Push(x interface{}) {
+	*q = append(*q, x.(rtreeCandidate[T]))
+}
+
+func (q *rtreeQueue[T]) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+func (t *rtree[T]) Nearest(p Point, fn func(b Boundary, value T, distanceInKM float64) bool) {
+
+	if p == nil {
+		return
+	}
+
+	// The nodes are queued by the lower bound of their distance, which is never more than the distances of the
+	// items under them, so each item is dequeued only after all the nearer ones.
+	q := &rtreeQueue[T]{{node: t.root}}
+	seen := make(map[*rtreeItem[T]]bool)
+
+	for q.Len() > 0 {
+		c := heap.Pop(q).(rtreeCandidate[T])
+
+		if c.item != nil {
+			if !fn(c.item.boundary, c.item.value, c.distance) {
+				return
+			}
+			continue
+		}
+
+		for _, e := range c.node.entries {
+			switch {
+			case !c.node.leaf:
+				heap.Push(q, rtreeCandidate[T]{distance: e.rect.lowerDistance(p), node: e.child})
+			case !seen[e.item]:
+				seen[e.item] = true
+				heap.Push(q, rtreeCandidate[T]{distance: getBoundaryDistance(e.item.boundary, p), item: e.item})
+			}
+		}
+	}
+}
+
+// packRTree groups the given entries into nodes using the sort-tile-recursive algorithm, where the entries are
+// sorted into vertical slices by their longitude, and each slice is sorted by latitude and cut into full nodes.
+func packRTree[T any](entries []rtreeEntry[T], leaf bool) []*rtreeNode[T] {
+
+	center := func(e rtreeEntry[T], lng bool) float64 {
+		if lng {
+			return e.rect.minLng + e.rect.maxLng
+		}
+		return e.rect.minLat + e.rect.maxLat
+	}
+
+	nodeCount := (len(entries) + rtreeMaxEntries - 1) / rtreeMaxEntries
+	sliceSize := int(math.Ceil(math.Sqrt(float64(nodeCount)))) * rtreeMaxEntries
+
+	sort.SliceStable(entries, func(i, j int) bool { return center(entries[i], true) < center(entries[j], true) })
+
+	nodes := []*rtreeNode[T]{}
+
+	for start := 0; start < len(entries); start += sliceSize {
+		slice := entries[start:int(math.Min(float64(start+sliceSize), float64(len(entries))))]
+
+		sort.SliceStable(slice, func(i, j int) bool { return center(slice[i], false) < center(slice[j], false) })
+
+		for from := 0; from < len(slice); from += rtreeMaxEntries {
+			to := int(math.Min(float64(from+rtreeMaxEntries), float64(len(slice))))
+			nodes = append(nodes, &rtreeNode[T]{leaf: leaf, entries: append([]rtreeEntry[T]{}, slice[from:to]...)})
+		}
+	}
+
+	return nodes
+}
+
+// NewRTree creates a new R-tree holding the given entries, which are bulk loaded using the sort-tile-recursive
+// algorithm, while the values inserted afterwards are placed using the R*-tree node split.
+// Entries having nil boundaries are left out.
+func NewRTree[T any](entries ...RTreeEntry[T]) RTree[T] {
+
+	t := &rtree[T]{root: &rtreeNode[T]{leaf: true}}
+	leaves := []rtreeEntry[T]{}
+
+	for _, e := range entries {
+		if e.Boundary == nil {
+			continue
+		}
+
+		item := &rtreeItem[T]{boundary: e.Boundary, value: e.Value, rects: getBoundaryRects(e.Boundary)}
+
+		for _, r := range item.rects {
+			leaves = append(leaves, rtreeEntry[T]{rect: r, item: item})
+		}
+
+		t.count++
+	}
+
+	if len(leaves) == 0 {
+		return t
+	}
+
+	nodes := packRTree(leaves, true)
+
+	for len(nodes) > 1 {
+		parents := make([]rtreeEntry[T], len(nodes))
+
+		for i, n := range nodes {
+			parents[i] = rtreeEntry[T]{rect: n.bounds(), child: n}
+		}
+
+		nodes = packRTree(parents, false)
+	}
+
+	t.root = nodes[0]
+
+	return t
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getRandomBoundary(r *rand.Rand) Boundary {
+	lat, lng := r.Float64()*170-85, r.Float64()*360-180
+	return NewBoundary(NewPoint(lat, lng), NewPoint(lat+r.Float64()*5, lng+r.Float64()*5))
+}
+
+func getBoundaryIntersects(a, b Boundary) bool {
+	for _, r1 := range getBoundaryRects(a) {
+		for _, r2 := range getBoundaryRects(b) {
+			if r1.intersects(r2) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func getSearchResults(t RTree[int], b Boundary) []int {
+	found := []int{}
+	t.Search(b, func(_ Boundary, v int) bool {
+		found = append(found, v)
+		return true
+	})
+	sort.Ints(found)
+	return found
+}
+
+func TestGetBoundaryNearest(t *testing.T) {
+
+	for _, tc := range []struct {
+		b Boundary
+		p Point
+	}{
+		{NewBoundary(NewPoint(0, 0), NewPoint(10, 10)), NewPoint(5, 5)},
+		{NewBoundary(NewPoint(0, 0), NewPoint(10, 10)), NewPoint(20, 5)},
+		{NewBoundary(NewPoint(0, 0), NewPoint(10, 10)), NewPoint(5, 20)},
+		{NewBoundary(NewPoint(0, 0), NewPoint(10, 10)), NewPoint(60, 40)},
+		{NewBoundary(NewPoint(0, 0), NewPoint(10, 10)), NewPoint(-30, -170)},
+		{NewBoundary(NewPoint(60, 170), NewPoint(70, -170)), NewPoint(65, 0)},
+		{NewBoundary(NewPoint(60, 170), NewPoint(70, -170)), NewPoint(80, -100)},
+		{NewBoundary(NewPoint(60, 0), NewPoint(90, 0)), NewPoint(50, 123)},
+		{NewBoundary(NewPoint(-10, 20), NewPoint(10, 30)), NewPoint(-89, 0)},
+	} {
+		nearest := getBoundaryNearest(tc.b, tc.p)
+		assert.True(t, boundaryContains(tc.b, nearest), "%v %v", tc.b, tc.p)
+
+		// Sampling the boundary finds no point nearer.
+		minLng, maxLng := getBoundaryLngRange(tc.b)
+		lower, upper := tc.b.Lower().Latitude(), tc.b.Upper().Latitude()
+
+		for i := 0; i <= 100; i++ {
+			for j := 0; j <= 100; j++ {
+				sample := NewPoint(lower+(upper-lower)*float64(i)/100, minLng+(maxLng-minLng)*float64(j)/100)
+				assert.True(t, GetDistance(tc.p, nearest) <= GetDistance(tc.p, sample)+0.001, "%v %v %v", tc.b, tc.p, sample)
+			}
+		}
+	}
+
+	assert.Equal(t, 0.0, getBoundaryDistance(NewBoundary(NewPoint(0, 0), NewPoint(10, 10)), NewPoint(5, 5)))
+	assert.InDelta(t, GetDistance(NewPoint(20, 5), NewPoint(10, 5)), getBoundaryDistance(NewBoundary(NewPoint(0, 0), NewPoint(10, 10)), NewPoint(20, 5)), DecimalPrecision)
+}
+
+func TestRect_LowerDistance(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 10000; i++ {
+		b := getRandomBoundary(r)
+		p := NewPoint(r.Float64()*180-90, r.Float64()*360-180)
+
+		if i%2 == 0 {
+			// Very close to the poles.
+			b = NewBoundary(NewPoint(89.9+r.Float64()*0.05, r.Float64()*360-180), NewPoint(89.95+r.Float64()*0.05, r.Float64()*360-180))
+			p = NewPoint(89.9+r.Float64()*0.1, r.Float64()*360-180)
+		}
+
+		for _, rc := range getBoundaryRects(b) {
+			for j := 0; j < 10; j++ {
+				sample := NewPoint(rc.minLat+(rc.maxLat-rc.minLat)*r.Float64(), rc.minLng+(rc.maxLng-rc.minLng)*r.Float64())
+				assert.True(t, rc.lowerDistance(p) <= GetDistance(p, sample), "%v %v %v", rc, p, sample)
+			}
+		}
+	}
+
+	assert.Equal(t, 0.0, rect{0, 10, 0, 10}.lowerDistance(NewPoint(5, 5)))
+	assert.InDelta(t, GetDistance(NewPoint(20, 5), NewPoint(10, 5)), rect{0, 10, 0, 10}.lowerDistance(NewPoint(20, 5)), 0.001)
+}
+
+func TestRTree(t *testing.T) {
+
+	tree := NewRTree[int]()
+
+	assert.Equal(t, 0, tree.Len())
+	assert.Empty(t, getSearchResults(tree, NewBoundary(NewPoint(-90, 0), NewPoint(90, 0))))
+
+	tree.Insert(nil, 0)
+	tree.Insert(NewBoundary(NewPoint(0, 0), NewPoint(1, 1)), 1)
+	tree.Insert(NewBoundary(NewPoint(10, 179), NewPoint(11, -179)), 2)
+	tree.Insert(NewBoundary(NewPoint(85, 0), NewPoint(90, 0)), 3)
+	tree.Insert(NewBoundary(NewPoint(5, 5), NewPoint(5, 5)), 4)
+
+	assert.Equal(t, 4, tree.Len())
+	assert.Equal(t, []int{1, 4}, getSearchResults(tree, NewBoundary(NewPoint(0.5, 0.5), NewPoint(5, 5))))
+	assert.Equal(t, []int{2}, getSearchResults(tree, NewBoundary(NewPoint(10.5, -179.5), NewPoint(10.5, -179.5))))
+	assert.Equal(t, []int{2}, getSearchResults(tree, NewBoundary(NewPoint(9, 170), NewPoint(12, 179.5))))
+	assert.Equal(t, []int{2}, getSearchResults(tree, NewBoundary(NewPoint(9, 170), NewPoint(12, -170))))
+	assert.Equal(t, []int{3}, getSearchResults(tree, NewBoundary(NewPoint(86, -120), NewPoint(86, -120))))
+	assert.Equal(t, []int{1, 2, 3, 4}, getSearchResults(tree, NewBoundary(NewPoint(-90, 0), NewPoint(90, 0))))
+	assert.Empty(t, getSearchResults(tree, nil))
+
+	count := 0
+	tree.Search(NewBoundary(NewPoint(-90, 0), NewPoint(90, 0)), func(_ Boundary, _ int) bool {
+		count++
+		return false
+	})
+	assert.Equal(t, 1, count)
+
+	nearest := []int{}
+	distances := []float64{}
+	tree.Nearest(NewPoint(4, 4), func(_ Boundary, v int, d float64) bool {
+		nearest = append(nearest, v)
+		distances = append(distances, d)
+		return true
+	})
+	assert.Equal(t, []int{4, 1, 3, 2}, nearest)
+	assert.InDelta(t, GetDistance(NewPoint(4, 4), NewPoint(5, 5)), distances[0], DecimalPrecision)
+
+	assert.False(t, tree.Delete(NewBoundary(NewPoint(0, 0), NewPoint(1, 2)), nil))
+	assert.False(t, tree.Delete(NewBoundary(NewPoint(0, 0), NewPoint(1, 1)), func(v int) bool { return v == 2 }))
+	assert.False(t, tree.Delete(nil, nil))
+	assert.True(t, tree.Delete(NewBoundary(NewPoint(10, 179), NewPoint(11, -179)), nil))
+	assert.Equal(t, 3, tree.Len())
+	assert.Equal(t, []int{1, 3, 4}, getSearchResults(tree, NewBoundary(NewPoint(-90, 0), NewPoint(90, 0))))
+}
+
+func TestRTree_Random(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+	boundaries := make([]Boundary, 2000)
+	entries := make([]RTreeEntry[int], len(boundaries))
+
+	for i := range boundaries {
+		boundaries[i] = getRandomBoundary(r)
+		entries[i] = RTreeEntry[int]{Boundary: boundaries[i], Value: i}
+	}
+
+	incremental := NewRTree[int]()
+
+	for i, b := range boundaries {
+		incremental.Insert(b, i)
+	}
+
+	bulk := NewRTree(entries...)
+	deleted := make(map[int]bool)
+
+	for _, tree := range []RTree[int]{incremental, bulk} {
+		for i := 0; i < len(boundaries); i += 3 {
+			assert.True(t, tree.Delete(boundaries[i], func(v int) bool { return v == i }))
+			deleted[i] = true
+		}
+
+		assert.Equal(t, len(boundaries)-len(deleted), tree.Len())
+
+		for i := 0; i < 50; i++ {
+			query := getRandomBoundary(r)
+			expected := []int{}
+
+			for j, b := range boundaries {
+				if !deleted[j] && getBoundaryIntersects(query, b) {
+					expected = append(expected, j)
+				}
+			}
+
+			assert.Equal(t, expected, getSearchResults(tree, query))
+
+			p := NewPoint(r.Float64()*180-90, r.Float64()*360-180)
+			distances := []float64{}
+
+			for j, b := range boundaries {
+				if !deleted[j] {
+					distances = append(distances, getBoundaryDistance(b, p))
+				}
+			}
+
+			sort.Float64s(distances)
+			found := []float64{}
+
+			tree.Nearest(p, func(b Boundary, v int, d float64) bool {
+				assert.False(t, deleted[v])
+				assert.Equal(t, getBoundaryDistance(b, p), d)
+				found = append(found, d)
+				return len(found) < 20
+			})
+
+			assert.Equal(t, distances[:20], found)
+		}
+	}
+
+	// Deleting everything leaves an empty tree.
+	for i, b := range boundaries {
+		if !deleted[i] {
+			assert.True(t, bulk.Delete(b, func(v int) bool { return v == i }))
+		}
+	}
+
+	assert.Equal(t, 0, bulk.Len())
+	assert.Empty(t, getSearchResults(bulk, NewBoundary(NewPoint(-90, 0), NewPoint(90, 0))))
+}
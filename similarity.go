@@ -0,0 +1,185 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrThresholdExceeded is returned when a distance calculation stops early because the distance is known to
+// exceed the given threshold.
+var ErrThresholdExceeded = errors.New("distance threshold exceeded")
+
+// Metric is a distance function between two geo-location points, such as GetDistance or GetEllipsoidDistance.
+type Metric func(p1 Point, p2 Point) float64
+
+// SimilarityOptions carries the options of the distance calculations between two sequences of geo-location points.
+type SimilarityOptions struct {
+	// Metric is the distance function between the points, when it's nil GetDistance is used instead.
+	Metric Metric
+	// Threshold is the largest distance of interest, once the distance is known to exceed it the calculation
+	// stops and ErrThresholdExceeded is returned, when it's zero the distance is always fully calculated.
+	Threshold float64
+}
+
+func (o SimilarityOptions) metric() Metric {
+	if o.Metric == nil {
+		return GetDistance
+	}
+	return o.Metric
+}
+
+func (o SimilarityOptions) exceeded(distance float64) bool {
+	return o.Threshold > 0 && distance > o.Threshold
+}
+
+// getNonNilPoints returns the given points without the nil ones.
+func getNonNilPoints(points []Point) []Point {
+
+	result := make([]Point, 0, len(points))
+
+	for _, p := range points {
+		if p != nil {
+			result = append(result, p)
+		}
+	}
+
+	return result
+}
+
+// getWarping runs the dynamic programming shared by the discrete Fréchet distance and dynamic time warping over
+// all the pairs of the given points, where combine merges the distance of a pair with the best result of the
+// pairs preceding it. Since combining never decreases the results, the calculation stops once a whole row of
+// results exceeds the threshold.
+func getWarping(a, b []Point, options SimilarityOptions, combine func(d, previous float64) float64) (float64, error) {
+
+	a, b = getNonNilPoints(a), getNonNilPoints(b)
+
+	if len(a) == 0 || len(b) == 0 {
+		return 0, ErrEmptyPointSet
+	}
+
+	metric := options.metric()
+	previous := make([]float64, len(b))
+	current := make([]float64, len(b))
+
+	for i := range a {
+		rowMin := math.Inf(1)
+
+		for j := range b {
+			d := metric(a[i], b[j])
+
+			switch {
+			case i == 0 && j == 0:
+				current[j] = d
+			case i == 0:
+				current[j] = combine(d, current[j-1])
+			case j == 0:
+				current[j] = combine(d, previous[j])
+			default:
+				current[j] = combine(d, math.Min(previous[j], math.Min(previous[j-1], current[j-1])))
+			}
+
+			rowMin = math.Min(rowMin, current[j])
+		}
+
+		if options.exceeded(rowMin) {
+			return 0, ErrThresholdExceeded
+		}
+
+		previous, current = current, previous
+	}
+
+	if result := previous[len(b)-1]; !options.exceeded(result) {
+		return result, nil
+	}
+
+	return 0, ErrThresholdExceeded
+}
+
+// GetFrechetDistance returns the discrete Fréchet distance between the two given sequences of geo-location
+// points, which is the shortest leash needed to walk both of them from start to end without going backwards.
+// Nil points are skipped, and ErrEmptyPointSet is returned if any of the sequences has no points.
+func GetFrechetDistance(a, b []Point, options SimilarityOptions) (float64, error) {
+	return getWarping(a, b, options, math.Max)
+}
+
+// GetDTWDistance returns the dynamic time warping distance between the two given sequences of geo-location
+// points, which is the smallest sum of distances between the pairs of points matched from start to end, where
+// each point is matched at least once without going backwards.
+// Nil points are skipped, and ErrEmptyPointSet is returned if any of the sequences has no points.
+func GetDTWDistance(a, b []Point, options SimilarityOptions) (float64, error) {
+	return getWarping(a, b, options, func(d, previous float64) float64 {
+		return d + previous
+	})
+}
+
+// getDirectedHausdorff returns the largest distance from any of the points of a to its nearest point of b,
+// or ErrThresholdExceeded once it's known to exceed the threshold.
+func getDirectedHausdorff(a, b []Point, options SimilarityOptions) (float64, error) {
+
+	metric := options.metric()
+	result := 0.0
+
+	for _, p := range a {
+		nearest := math.Inf(1)
+
+		for _, q := range b {
+			nearest = math.Min(nearest, metric(p, q))
+
+			// The point can't increase the result once it has a nearer point.
+			if nearest <= result {
+				break
+			}
+		}
+
+		if options.exceeded(nearest) {
+			return 0, ErrThresholdExceeded
+		}
+
+		result = math.Max(result, nearest)
+	}
+
+	return result, nil
+}
+
+// GetHausdorffDistance returns the Hausdorff distance between the two given sequences of geo-location points,
+// which is the largest distance from any point of a sequence to its nearest point of the other one.
+// Nil points are skipped, and ErrEmptyPointSet is returned if any of the sequences has no points.
+func GetHausdorffDistance(a, b []Point, options SimilarityOptions) (float64, error) {
+
+	a, b = getNonNilPoints(a), getNonNilPoints(b)
+
+	if len(a) == 0 || len(b) == 0 {
+		return 0, ErrEmptyPointSet
+	}
+
+	ab, err := getDirectedHausdorff(a, b, options)
+
+	if err != nil {
+		return 0, err
+	}
+
+	ba, err := getDirectedHausdorff(b, a, options)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return math.Max(ab, ba), nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFrechetDistance(t *testing.T) {
+
+	a := []Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(0, 2)}
+	b := []Point{NewPoint(1, 0), nil, NewPoint(1, 1), NewPoint(1, 2)}
+	degree := GetDistance(NewPoint(0, 0), NewPoint(1, 0))
+
+	d, err := GetFrechetDistance(a, b, SimilarityOptions{})
+	assert.Nil(t, err)
+	assert.InDelta(t, degree, d, DecimalPrecision)
+
+	// Walking the reversed sequence needs a leash from one end to the other.
+	d, err = GetFrechetDistance(a, reverseRing(a), SimilarityOptions{})
+	assert.Nil(t, err)
+	assert.InDelta(t, GetDistance(a[0], a[2]), d, DecimalPrecision)
+
+	// Unlike Hausdorff, Fréchet takes the order of the points into account.
+	h, err := GetHausdorffDistance(a, reverseRing(a), SimilarityOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, h)
+
+	d, err = GetFrechetDistance(a, b, SimilarityOptions{Metric: GetEllipsoidDistance})
+	assert.Nil(t, err)
+	assert.InDelta(t, GetEllipsoidDistance(NewPoint(0, 0), NewPoint(1, 0)), d, DecimalPrecision)
+
+	_, err = GetFrechetDistance(a, b, SimilarityOptions{Threshold: degree / 2})
+	assert.Equal(t, ErrThresholdExceeded, err)
+
+	d, err = GetFrechetDistance(a, b, SimilarityOptions{Threshold: degree * 2})
+	assert.Nil(t, err)
+	assert.InDelta(t, degree, d, DecimalPrecision)
+
+	_, err = GetFrechetDistance(nil, b, SimilarityOptions{})
+	assert.Equal(t, ErrEmptyPointSet, err)
+}
+
+func TestGetDTWDistance(t *testing.T) {
+
+	a := []Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(0, 2)}
+	b := []Point{NewPoint(0, 0), NewPoint(0, 0), NewPoint(0, 1), NewPoint(0, 2), NewPoint(0, 2)}
+
+	// Repeated points are matched to the same points at no cost.
+	d, err := GetDTWDistance(a, b, SimilarityOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, d)
+
+	c := []Point{NewPoint(1, 0), NewPoint(1, 1), NewPoint(1, 2)}
+	degree := GetDistance(NewPoint(0, 0), NewPoint(1, 0))
+
+	d, err = GetDTWDistance(a, c, SimilarityOptions{})
+	assert.Nil(t, err)
+	assert.InDelta(t, 3*degree, d, 0.1)
+
+	_, err = GetDTWDistance(a, c, SimilarityOptions{Threshold: 2 * degree})
+	assert.Equal(t, ErrThresholdExceeded, err)
+
+	_, err = GetDTWDistance(a, []Point{nil}, SimilarityOptions{})
+	assert.Equal(t, ErrEmptyPointSet, err)
+}
+
+func TestGetHausdorffDistance(t *testing.T) {
+
+	a := []Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(0, 2)}
+	b := []Point{NewPoint(0, 0), NewPoint(0, 2)}
+
+	// The middle point of a is the farthest from b, while all the points of b are in a.
+	d, err := GetHausdorffDistance(a, b, SimilarityOptions{})
+	assert.Nil(t, err)
+	assert.InDelta(t, GetDistance(NewPoint(0, 0), NewPoint(0, 1)), d, DecimalPrecision)
+
+	d, err = GetHausdorffDistance(b, a, SimilarityOptions{})
+	assert.Nil(t, err)
+	assert.InDelta(t, GetDistance(NewPoint(0, 0), NewPoint(0, 1)), d, DecimalPrecision)
+
+	_, err = GetHausdorffDistance(a, b, SimilarityOptions{Threshold: 100})
+	assert.Equal(t, ErrThresholdExceeded, err)
+
+	// Across the antimeridian.
+	d, err = GetHausdorffDistance([]Point{NewPoint(0, 179.9)}, []Point{NewPoint(0, -179.9)}, SimilarityOptions{})
+	assert.Nil(t, err)
+	assert.InDelta(t, 22.2, d, 0.1)
+
+	_, err = GetHausdorffDistance(a, nil, SimilarityOptions{})
+	assert.Equal(t, ErrEmptyPointSet, err)
+}
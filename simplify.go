@@ -0,0 +1,272 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"container/heap"
+	"math"
+)
+
+// metersToAngle converts a distance in meters on the surface of earth to a central angle in radians.
+func metersToAngle(meters float64) float64 {
+	return kmToAngle(meters / 1000)
+}
+
+// SimplifyDouglasPeucker simplifies the line going through the given geo-location points using the
+// Ramer-Douglas-Peucker algorithm, dropping the points that are within the given tolerance in meters from the
+// simplified line.
+// The distances are measured on the sphere from the great circle arcs connecting the kept points.
+// The ends of an open line are always kept. A closed line, having its first point repeated at the end, is a ring
+// whose start point is kept as well if keepRingStart is true, otherwise it can be dropped like any other point of
+// the ring, in which case the ring is closed again at its new start point. keepRingStart has no effect on open
+// lines.
+// Nil points are left out.
+// The simplified points are returned along with their indices in the given slice.
+func SimplifyDouglasPeucker(points []Point, toleranceInMeters float64, keepRingStart bool) ([]Point, []int) {
+	return simplifyNonNil(points, func(points []Point) ([]Point, []int) {
+		return simplifyDouglasPeucker(points, toleranceInMeters, keepRingStart)
+	})
+}
+
+func simplifyDouglasPeucker(points []Point, toleranceInMeters float64, keepRingStart bool) ([]Point, []int) {
+
+	if len(points) < 3 {
+		return getKeptPoints(points, makeRange(len(points)), toleranceInMeters, keepRingStart)
+	}
+
+	vectors := make([]vector, len(points))
+
+	for i, p := range points {
+		vectors[i] = toVector(p)
+	}
+
+	tolerance := metersToAngle(toleranceInMeters)
+	kept := make([]bool, len(points))
+	kept[0] = true
+	kept[len(points)-1] = true
+
+	// Using an explicit stack of segments instead of recursion, since GPS tracks may have many thousands of points.
+	stack := [][2]int{{0, len(points) - 1}}
+
+	for len(stack) > 0 {
+		first, last := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+
+		farthest, farthestAngle := -1, tolerance
+
+		for i := first + 1; i < last; i++ {
+			closest, _ := closestOnArc(vectors[i], vectors[first], vectors[last])
+
+			if a := vectors[i].angle(closest); a > farthestAngle {
+				farthest, farthestAngle = i, a
+			}
+		}
+
+		if farthest != -1 {
+			kept[farthest] = true
+			stack = append(stack, [2]int{first, farthest}, [2]int{farthest, last})
+		}
+	}
+
+	indices := []int{}
+
+	for i, k := range kept {
+		if k {
+			indices = append(indices, i)
+		}
+	}
+
+	return getKeptPoints(points, indices, toleranceInMeters, keepRingStart)
+}
+
+// vwPoint is a point of the line being simplified by the Visvalingam-Whyatt algorithm.
+type vwPoint struct {
+	index      int
+	area       float64
+	heapIndex  int
+	prev, next *vwPoint
+}
+
+// vwHeap is a min heap of the line points by their effective area.
+type vwHeap []*vwPoint
+
+func (h vwHeap) Len() int {
+	return len(h)
+}
+
+func (h vwHeap) Less(i, j int) bool {
+	return h[i].area < h[j].area
+}
+
+func (h vwHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIndex = i
+	h[j].heapIndex = j
+}
+
+func (h *vwHeap) Push(x interface{}) {
+	p := x.(*vwPoint)
+	p.heapIndex = len(*h)
+	*h = append(*h, p)
+}
+
+func (h *vwHeap) Pop() interface{} {
+	old := *h
+	p := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return p
+}
+
+// SimplifyVisvalingamWhyatt simplifies the line going through the given geo-location points using the
+// Visvalingam-Whyatt algorithm, repeatedly dropping the point having the least effective area, which is the area
+// of the triangle it forms with its two neighbours, as long as that area is less than the area of a square
+// having the given tolerance in meters as its side.
+// The areas are the ones of the spherical triangles on the surface of earth.
+// The ends of an open line are always kept. A closed line, having its first point repeated at the end, is a ring
+// whose start point is kept as well if keepRingStart is true, otherwise it can be dropped like any other point of
+// the ring, in which case the ring is closed again at its new start point. keepRingStart has no effect on open
+// lines.
+// Nil points are left out.
+// The simplified points are returned along with their indices in the given slice.
+func SimplifyVisvalingamWhyatt(points []Point, toleranceInMeters float64, keepRingStart bool) ([]Point, []int) {
+	return simplifyNonNil(points, func(points []Point) ([]Point, []int) {
+		return simplifyVisvalingamWhyatt(points, toleranceInMeters, keepRingStart)
+	})
+}
+
+func simplifyVisvalingamWhyatt(points []Point, toleranceInMeters float64, keepRingStart bool) ([]Point, []int) {
+
+	if len(points) < 3 {
+		return getKeptPoints(points, makeRange(len(points)), toleranceInMeters, keepRingStart)
+	}
+
+	vectors := make([]vector, len(points))
+
+	for i, p := range points {
+		vectors[i] = toVector(p)
+	}
+
+	tolerance := metersToAngle(toleranceInMeters)
+	threshold := tolerance * tolerance
+	line := make([]*vwPoint, len(points))
+
+	for i := range line {
+		line[i] = &vwPoint{index: i}
+
+		if i > 0 {
+			line[i].prev = line[i-1]
+			line[i-1].next = line[i]
+		}
+	}
+
+	area := func(p *vwPoint) float64 {
+		return triangleArea(vectors[p.prev.index], vectors[p.index], vectors[p.next.index])
+	}
+
+	h := make(vwHeap, 0, len(points)-2)
+
+	for _, p := range line[1 : len(line)-1] {
+		p.area = area(p)
+		heap.Push(&h, p)
+	}
+
+	for h.Len() > 0 && h[0].area < threshold {
+		p := heap.Pop(&h).(*vwPoint)
+		p.prev.next = p.next
+		p.next.prev = p.prev
+
+		// A neighbour's area never goes below the one of the dropped point, so that points are dropped in the
+		// order of their significance.
+		for _, n := range []*vwPoint{p.prev, p.next} {
+			if n.prev != nil && n.next != nil {
+				n.area = math.Max(area(n), p.area)
+				heap.Fix(&h, n.heapIndex)
+			}
+		}
+	}
+
+	indices := []int{}
+
+	for p := line[0]; p != nil; p = p.next {
+		indices = append(indices, p.index)
+	}
+
+	return getKeptPoints(points, indices, toleranceInMeters, keepRingStart)
+}
+
+// simplifyNonNil calls the given simplification with the non nil points, returning the points it keeps along with
+// their indices in the given slice.
+func simplifyNonNil(points []Point, simplify func(points []Point) ([]Point, []int)) ([]Point, []int) {
+
+	valid := make([]Point, 0, len(points))
+	original := make([]int, 0, len(points))
+
+	for i, p := range points {
+		if p != nil {
+			valid = append(valid, p)
+			original = append(original, i)
+		}
+	}
+
+	kept, indices := simplify(valid)
+
+	for i, index := range indices {
+		indices[i] = original[index]
+	}
+
+	return kept, indices
+}
+
+// makeRange returns a slice of the integers from zero up to the given count, excluded.
+func makeRange(count int) []int {
+	r := make([]int, count)
+
+	for i := range r {
+		r[i] = i
+	}
+
+	return r
+}
+
+// getKeptPoints returns the points at the given indices, after dropping the start point of a closed line if it's
+// not to be kept and it's within the tolerance from the arc joining its two neighbours.
+func getKeptPoints(points []Point, indices []int, toleranceInMeters float64, keepRingStart bool) ([]Point, []int) {
+
+	if !keepRingStart && len(indices) > 4 {
+		first := toVector(points[indices[0]])
+		last := len(indices) - 1
+
+		if first.angle(toVector(points[indices[last]])) < epsilon {
+			prev := toVector(points[indices[last-1]])
+			next := toVector(points[indices[1]])
+			closest, _ := closestOnArc(first, prev, next)
+
+			if first.angle(closest) <= metersToAngle(toleranceInMeters) {
+				// Closing the ring again at the next point.
+				indices = append(append([]int{}, indices[1:last]...), indices[1])
+			}
+		}
+	}
+
+	kept := make([]Point, len(indices))
+
+	for i, index := range indices {
+		kept[i] = points[index]
+	}
+
+	return kept, indices
+}
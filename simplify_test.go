@@ -0,0 +1,158 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// getZigzagTrack returns a track along the Equator with a tiny zigzag and a single significant detour.
+func getZigzagTrack() []Point {
+
+	track := []Point{}
+
+	for i := 0; i <= 100; i++ {
+		lat := 0.00001 * float64(i%2)
+
+		if i == 50 {
+			lat = 0.01
+		}
+
+		track = append(track, NewPoint(lat, 179.95+0.001*float64(i)))
+	}
+
+	return track
+}
+
+func TestSimplifyDouglasPeucker(t *testing.T) {
+
+	points, indices := SimplifyDouglasPeucker(nil, 10, true)
+	assert.Empty(t, points)
+	assert.Empty(t, indices)
+
+	points, indices = SimplifyDouglasPeucker(getZigzagTrack(), 10, true)
+	assert.Equal(t, []int{0, 49, 50, 51, 100}, indices)
+	assert.Len(t, points, 5)
+
+	points, indices = SimplifyDouglasPeucker(getZigzagTrack(), 2000, true)
+	assert.Equal(t, []int{0, 100}, indices)
+
+	_, indices = SimplifyDouglasPeucker(getZigzagTrack(), 0.1, true)
+	assert.Len(t, indices, 101)
+}
+
+func TestSimplifyVisvalingamWhyatt(t *testing.T) {
+
+	points, indices := SimplifyVisvalingamWhyatt([]Point{NewPoint(0, 0)}, 10, true)
+	assert.Len(t, points, 1)
+	assert.Equal(t, []int{0}, indices)
+
+	_, indices = SimplifyVisvalingamWhyatt(getZigzagTrack(), 100, true)
+	assert.Equal(t, []int{0, 49, 50, 51, 100}, indices)
+
+	_, indices = SimplifyVisvalingamWhyatt(getZigzagTrack(), 2000, true)
+	assert.Equal(t, []int{0, 100}, indices)
+
+	_, indices = SimplifyVisvalingamWhyatt(getZigzagTrack(), 0.1, true)
+	assert.Len(t, indices, 101)
+}
+
+func TestSimplify_RingStart(t *testing.T) {
+
+	// A closed ring starting in the middle of the southern edge of a square.
+	ring := []Point{NewPoint(0, 0.005), NewPoint(0, 0.01), NewPoint(0.01, 0.01), NewPoint(0.01, 0), NewPoint(0, 0), NewPoint(0, 0.005)}
+
+	points, indices := SimplifyDouglasPeucker(ring, 5, true)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, indices)
+	assert.Len(t, points, 6)
+
+	points, indices = SimplifyDouglasPeucker(ring, 5, false)
+	assert.Equal(t, []int{1, 2, 3, 4, 1}, indices)
+	assert.Equal(t, points[0], points[len(points)-1])
+
+	_, indices = SimplifyVisvalingamWhyatt(ring, 5, true)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, indices)
+
+	_, indices = SimplifyVisvalingamWhyatt(ring, 5, false)
+	assert.Equal(t, []int{1, 2, 3, 4, 1}, indices)
+
+	// The ends of open lines are always kept.
+	line := ring[:5]
+
+	_, indices = SimplifyDouglasPeucker(line, 5, false)
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, indices)
+}
+
+func TestSimplify_Nil(t *testing.T) {
+
+	track := getZigzagTrack()
+	withNils := []Point{nil}
+
+	for _, p := range track {
+		withNils = append(withNils, p, nil)
+	}
+
+	// The indices refer to the given slice, nils included.
+	points, indices := SimplifyDouglasPeucker(withNils, 10, true)
+	assert.Equal(t, []int{1, 99, 101, 103, 201}, indices)
+	assert.Equal(t, []Point{track[0], track[49], track[50], track[51], track[100]}, points)
+
+	points, indices = SimplifyVisvalingamWhyatt(withNils, 100, true)
+	assert.Equal(t, []int{1, 99, 101, 103, 201}, indices)
+	assert.Equal(t, []Point{track[0], track[49], track[50], track[51], track[100]}, points)
+
+	points, indices = SimplifyDouglasPeucker([]Point{nil, nil}, 10, true)
+	assert.Empty(t, points)
+	assert.Empty(t, indices)
+
+	points, indices = SimplifyVisvalingamWhyatt([]Point{nil, NewPoint(1, 1), nil}, 10, true)
+	assert.Equal(t, []int{1}, indices)
+	assert.Len(t, points, 1)
+}
+
+func TestSimplifyDouglasPeucker_Tolerance(t *testing.T) {
+
+	track := make([]Point, 1000)
+	lat, lng := 45.0, 179.0
+
+	for i := range track {
+		lat += rand.Float64()*0.002 - 0.001
+		lng += rand.Float64() * 0.002
+		track[i] = NewPoint(lat, lng)
+	}
+
+	tolerance := 50.0
+	points, indices := SimplifyDouglasPeucker(track, tolerance, true)
+	assert.True(t, len(points) < len(track))
+
+	// Every dropped point has to be within the tolerance from the simplified line segment replacing it.
+	for k := 0; k < len(indices)-1; k++ {
+		a, b := toVector(track[indices[k]]), toVector(track[indices[k+1]])
+
+		for i := indices[k] + 1; i < indices[k+1]; i++ {
+			v := toVector(track[i])
+			c, _ := closestOnArc(v, a, b)
+			assert.True(t, angleToKM(v.angle(c))*1000 <= tolerance+1e-6)
+		}
+	}
+
+	assert.False(t, math.IsNaN(points[0].Latitude()))
+}
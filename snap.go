@@ -0,0 +1,130 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Snap is the nearest location on a line to a given geo-location point.
+type Snap interface {
+	// Point returns the nearest point on the line.
+	Point() Point
+	// Segment returns the index of the line point starting the segment having the nearest point.
+	Segment() int
+	// Fraction returns the fraction of the segment length from its start to the nearest point.
+	Fraction() float64
+	// Distance returns the distance in kilometers on the sphere from the given point to the nearest point.
+	Distance() float64
+}
+
+type snap struct {
+	point    Point
+	segment  int
+	fraction float64
+	distance float64
+}
+
+func (s *snap) String() string {
+	return fmt.Sprintf("(%v, %v, %v, %vkm)", s.Point(), s.Segment(), s.Fraction(), s.Distance())
+}
+
+func (s *snap) Point() Point {
+	if s != nil {
+		return s.point
+	}
+	return nil
+}
+
+func (s *snap) Segment() int {
+	if s != nil {
+		return s.segment
+	}
+	return 0
+}
+
+func (s *snap) Fraction() float64 {
+	if s != nil {
+		return s.fraction
+	}
+	return 0.0
+}
+
+func (s *snap) Distance() float64 {
+	if s != nil {
+		return s.distance
+	}
+	return 0.0
+}
+
+// SnapToLine returns the nearest location to the given point on the line going through the given points along
+// great circle arcs, which can be a ring as well when its first point is repeated at its end.
+// Nil points are skipped, so a segment goes from a point to the next non-nil one, and a line of a single point
+// has that point as its nearest location.
+// It returns nil if the given point is nil or the line has no points, and the first nearest location if there
+// are many.
+func SnapToLine(p Point, line []Point) Snap {
+
+	if p == nil {
+		return nil
+	}
+
+	v := toVector(p)
+	var result *snap
+	best := math.Inf(1)
+	previous := -1
+
+	consider := func(point Point, x vector, segment int, fraction float64) {
+		if angle := v.angle(x); angle < best {
+			best = angle
+			result = &snap{point: point, segment: segment, fraction: fraction, distance: angleToKM(angle)}
+		}
+	}
+
+	for i, current := range line {
+		if current == nil {
+			continue
+		}
+
+		if previous == -1 {
+			consider(current, toVector(current), i, 0)
+			previous = i
+			continue
+		}
+
+		start := line[previous]
+		x, fraction := closestOnArc(v, toVector(start), toVector(current))
+
+		switch fraction {
+		case 0:
+			consider(start, x, previous, 0)
+		case 1:
+			consider(current, x, previous, 1)
+		default:
+			consider(x.toPoint(), x, previous, fraction)
+		}
+
+		previous = i
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return result
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnap_Nil(t *testing.T) {
+
+	var s *snap
+	var sn Snap = s
+
+	assert.Nil(t, sn.Point())
+	assert.Equal(t, 0, sn.Segment())
+	assert.Equal(t, 0.0, sn.Fraction())
+	assert.Equal(t, 0.0, sn.Distance())
+
+	assert.Nil(t, SnapToLine(nil, []Point{NewPoint(0, 0)}))
+	assert.Nil(t, SnapToLine(NewPoint(0, 0), nil))
+	assert.Nil(t, SnapToLine(NewPoint(0, 0), []Point{nil}))
+}
+
+func TestSnapToLine(t *testing.T) {
+
+	line := []Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1)}
+	degree := angleToKM(radians)
+
+	s := SnapToLine(NewPoint(0.1, 0.25), line)
+	assert.Equal(t, 0, s.Segment())
+	assert.InDelta(t, 0.25, s.Fraction(), DecimalPrecision)
+	assert.InDelta(t, 0, s.Point().Latitude(), DecimalPrecision)
+	assert.InDelta(t, 0.25, s.Point().Longitude(), DecimalPrecision)
+	assert.InDelta(t, 0.1*degree, s.Distance(), 1e-3)
+
+	s = SnapToLine(NewPoint(0.5, 1.2), line)
+	assert.Equal(t, 1, s.Segment())
+	assert.InDelta(t, 0.5, s.Fraction(), 1e-4)
+	assert.InDelta(t, 1, s.Point().Longitude(), DecimalPrecision)
+
+	// Beyond the line ends.
+	s = SnapToLine(NewPoint(0, -1), line)
+	assert.Equal(t, 0, s.Segment())
+	assert.Equal(t, 0.0, s.Fraction())
+	assert.Equal(t, line[0], s.Point())
+	assert.InDelta(t, degree, s.Distance(), 1e-3)
+
+	s = SnapToLine(NewPoint(2, 1), line)
+	assert.Equal(t, 1, s.Segment())
+	assert.Equal(t, 1.0, s.Fraction())
+	assert.Equal(t, line[2], s.Point())
+
+	// On the line.
+	s = SnapToLine(NewPoint(0, 0.5), line)
+	assert.InDelta(t, 0, s.Distance(), 1e-6)
+
+	// A ring around the antimeridian, with a nil point skipped.
+	ring := []Point{NewPoint(0, 179), NewPoint(0, -179), nil, NewPoint(1, -179), NewPoint(1, 179), NewPoint(0, 179)}
+	s = SnapToLine(NewPoint(0.5, -179.1), ring)
+	assert.Equal(t, 1, s.Segment())
+	assert.InDelta(t, 0.5, s.Fraction(), 1e-4)
+	assert.InDelta(t, -179, s.Point().Longitude(), DecimalPrecision)
+
+	s = SnapToLine(NewPoint(0.5, 178.5), ring)
+	assert.Equal(t, 4, s.Segment())
+
+	s = SnapToLine(NewPoint(3, 3), []Point{NewPoint(1, 1)})
+	assert.Equal(t, 0, s.Segment())
+	assert.Equal(t, NewPoint(1, 1), s.Point())
+}
@@ -0,0 +1,377 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Fix is a geo-location point recorded at a given time, such as a GPS reading.
+type Fix interface {
+	// Point returns the recorded geo-location point.
+	Point() Point
+	// Time returns the time the point was recorded at.
+	Time() time.Time
+	// Accuracy returns the horizontal accuracy of the point in meters, and whether it's known.
+	Accuracy() (float64, bool)
+	// Altitude returns the altitude of the point in meters, and whether it's known.
+	Altitude() (float64, bool)
+}
+
+type fix struct {
+	point    Point
+	time     time.Time
+	accuracy float64
+	altitude float64
+}
+
+func (f *fix) String() string {
+	return fmt.Sprintf("(%v @ %v)", f.Point(), f.Time().Format(time.RFC3339Nano))
+}
+
+func (f *fix) Point() Point {
+	if f != nil {
+		return f.point
+	}
+	return nil
+}
+
+func (f *fix) Time() time.Time {
+	if f != nil {
+		return f.time
+	}
+	return time.Time{}
+}
+
+func (f *fix) Accuracy() (float64, bool) {
+	if f != nil && !math.IsNaN(f.accuracy) {
+		return f.accuracy, true
+	}
+	return 0.0, false
+}
+
+func (f *fix) Altitude() (float64, bool) {
+	if f != nil && !math.IsNaN(f.altitude) {
+		return f.altitude, true
+	}
+	return 0.0, false
+}
+
+// NewFix creates a new fix instance, given the recorded point and time, with unknown accuracy and altitude.
+func NewFix(point Point, t time.Time) Fix {
+	return NewDetailedFix(point, t, math.NaN(), math.NaN())
+}
+
+// NewDetailedFix creates a new fix instance, given the recorded point and time, along with its horizontal
+// accuracy and altitude in meters, where NaN stands for an unknown value.
+func NewDetailedFix(point Point, t time.Time, accuracyInMeters float64, altitudeInMeters float64) Fix {
+	return &fix{point: point, time: t, accuracy: accuracyInMeters, altitude: altitudeInMeters}
+}
+
+// StayPoint is a place where a track stayed within a given radius for a while.
+type StayPoint interface {
+	// Center returns the spherical centroid of the fixes recorded during the stay.
+	Center() Point
+	// Arrival returns the time of the first fix of the stay.
+	Arrival() time.Time
+	// Departure returns the time of the last fix of the stay.
+	Departure() time.Time
+	// Indices returns the indices of the first and the last fixes of the stay in the track.
+	Indices() (int, int)
+}
+
+type stayPoint struct {
+	center      Point
+	first, last Fix
+	start, end  int
+}
+
+func (s *stayPoint) String() string {
+	return fmt.Sprintf("(%v, %v -> %v)", s.Center(), s.Arrival().Format(time.RFC3339), s.Departure().Format(time.RFC3339))
+}
+
+func (s *stayPoint) Center() Point {
+	if s != nil {
+		return s.center
+	}
+	return nil
+}
+
+func (s *stayPoint) Arrival() time.Time {
+	if s != nil {
+		return s.first.Time()
+	}
+	return time.Time{}
+}
+
+func (s *stayPoint) Departure() time.Time {
+	if s != nil {
+		return s.last.Time()
+	}
+	return time.Time{}
+}
+
+func (s *stayPoint) Indices() (int, int) {
+	if s != nil {
+		return s.start, s.end
+	}
+	return 0, 0
+}
+
+// Track is a sequence of fixes ordered by time, such as the path of a vehicle, where consecutive fixes are
+// connected by great circle arcs.
+type Track interface {
+	// Fixes returns the fixes of the track ordered by time.
+	Fixes() []Fix
+	// Distance returns the total distance in kilometers between the consecutive fixes, using GetDistance.
+	Distance() float64
+	// Duration returns the time elapsed between the first and the last fixes.
+	Duration() time.Duration
+	// Speeds returns the speed in kilometers per hour of each segment between consecutive fixes, which is
+	// zero for segments having no duration.
+	Speeds() []float64
+	// Headings returns the initial bearing in degrees clockwise from the north of each segment between
+	// consecutive fixes, which is NaN for segments having no length.
+	Headings() []float64
+	// MovingTime returns the total duration of the segments having at least the given speed in kilometers
+	// per hour.
+	MovingTime(minSpeedInKMH float64) time.Duration
+	// StoppedTime returns the total duration of the segments slower than the given speed in kilometers per hour.
+	StoppedTime(minSpeedInKMH float64) time.Duration
+	// StayPoints returns the places where the track stayed within the given radius in kilometers from the
+	// first fix of the stay for at least the given duration, in order.
+	StayPoints(radiusInKM float64, minDwell time.Duration) []StayPoint
+	// PointAt returns the position at the given time, interpolated along the great circle between the fixes
+	// around it, or nil if the time is out of the track time range.
+	PointAt(t time.Time) Point
+}
+
+type track struct {
+	fixes []Fix
+}
+
+func (t *track) String() string {
+	return fmt.Sprintf("%v", t.Fixes())
+}
+
+func (t *track) Fixes() []Fix {
+	if t != nil {
+		return t.fixes
+	}
+	return nil
+}
+
+func (t *track) Distance() float64 {
+
+	if t == nil {
+		return 0.0
+	}
+
+	sum := 0.0
+
+	for i := 1; i < len(t.fixes); i++ {
+		sum += GetDistance(t.fixes[i-1].Point(), t.fixes[i].Point())
+	}
+
+	return sum
+}
+
+func (t *track) Duration() time.Duration {
+
+	if t == nil || len(t.fixes) == 0 {
+		return 0
+	}
+
+	return t.fixes[len(t.fixes)-1].Time().Sub(t.fixes[0].Time())
+}
+
+func (t *track) Speeds() []float64 {
+
+	if t == nil || len(t.fixes) < 2 {
+		return nil
+	}
+
+	speeds := make([]float64, len(t.fixes)-1)
+
+	for i := range speeds {
+		a, b := t.fixes[i], t.fixes[i+1]
+
+		if hours := b.Time().Sub(a.Time()).Hours(); hours > 0 {
+			speeds[i] = GetDistance(a.Point(), b.Point()) / hours
+		}
+	}
+
+	return speeds
+}
+
+func (t *track) Headings() []float64 {
+
+	if t == nil || len(t.fixes) < 2 {
+		return nil
+	}
+
+	headings := make([]float64, len(t.fixes)-1)
+
+	for i := range headings {
+		headings[i] = getBearing(toVector(t.fixes[i].Point()), toVector(t.fixes[i+1].Point()))
+	}
+
+	return headings
+}
+
+// splitTime returns the total durations of the segments having at least the given speed, and of the slower ones.
+func (t *track) splitTime(minSpeedInKMH float64) (time.Duration, time.Duration) {
+
+	var moving, stopped time.Duration
+
+	for i, speed := range t.Speeds() {
+		d := t.fixes[i+1].Time().Sub(t.fixes[i].Time())
+
+		if speed >= minSpeedInKMH {
+			moving += d
+		} else {
+			stopped += d
+		}
+	}
+
+	return moving, stopped
+}
+
+func (t *track) MovingTime(minSpeedInKMH float64) time.Duration {
+	moving, _ := t.splitTime(minSpeedInKMH)
+	return moving
+}
+
+func (t *track) StoppedTime(minSpeedInKMH float64) time.Duration {
+	_, stopped := t.splitTime(minSpeedInKMH)
+	return stopped
+}
+
+func (t *track) StayPoints(radiusInKM float64, minDwell time.Duration) []StayPoint {
+
+	if t == nil {
+		return nil
+	}
+
+	result := []StayPoint{}
+	n := len(t.fixes)
+
+	for i := 0; i < n; {
+		j := i + 1
+
+		for j < n && GetDistance(t.fixes[i].Point(), t.fixes[j].Point()) <= radiusInKM {
+			j++
+		}
+
+		if j-1 > i && t.fixes[j-1].Time().Sub(t.fixes[i].Time()) >= minDwell {
+			points := make([]Point, 0, j-i)
+
+			for _, f := range t.fixes[i:j] {
+				points = append(points, f.Point())
+			}
+
+			result = append(result, &stayPoint{
+				center: getCentroid(points),
+				first:  t.fixes[i],
+				last:   t.fixes[j-1],
+				start:  i,
+				end:    j - 1,
+			})
+
+			i = j
+			continue
+		}
+
+		i++
+	}
+
+	return result
+}
+
+func (t *track) PointAt(at time.Time) Point {
+
+	if t == nil || len(t.fixes) == 0 {
+		return nil
+	}
+
+	n := len(t.fixes)
+
+	if at.Before(t.fixes[0].Time()) || at.After(t.fixes[n-1].Time()) {
+		return nil
+	}
+
+	// The first fix recorded at or after the given time.
+	i := sort.Search(n, func(i int) bool {
+		return !t.fixes[i].Time().Before(at)
+	})
+
+	if t.fixes[i].Time().Equal(at) {
+		return t.fixes[i].Point()
+	}
+
+	a, b := t.fixes[i-1], t.fixes[i]
+	fraction := float64(at.Sub(a.Time())) / float64(b.Time().Sub(a.Time()))
+
+	return interpolate(toVector(a.Point()), toVector(b.Point()), fraction).toPoint()
+}
+
+// getBearing returns the initial bearing in degrees clockwise from the north, between 0 and 360, of the great
+// circle arc going from v to w, or NaN if they are the same.
+func getBearing(v, w vector) float64 {
+
+	d := direction(v, w)
+
+	if v.angle(w) < epsilon || d.norm() < epsilon {
+		return math.NaN()
+	}
+
+	north, east := getTangents(v)
+	bearing := math.Atan2(d.dot(east), d.dot(north)) / radians
+
+	if bearing < 0 {
+		bearing += 360
+	}
+
+	// A tiny negative bearing rounds up to 360 when wrapped.
+	if bearing >= 360 {
+		bearing = 0
+	}
+
+	return bearing
+}
+
+// NewTrack creates a new track instance out of the given fixes, ordering them by time, while keeping the order
+// of the fixes recorded at the same time. The nil fixes and the fixes with no points are skipped.
+func NewTrack(fixes []Fix) Track {
+
+	sorted := make([]Fix, 0, len(fixes))
+
+	for _, f := range fixes {
+		if f != nil && f.Point() != nil {
+			sorted = append(sorted, f)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time().Before(sorted[j].Time())
+	})
+
+	return &track{fixes: sorted}
+}
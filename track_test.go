@@ -0,0 +1,165 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFix(t *testing.T) {
+
+	var f *fix
+	var fx Fix = f
+
+	assert.Nil(t, fx.Point())
+	assert.True(t, fx.Time().IsZero())
+	_, ok := fx.Accuracy()
+	assert.False(t, ok)
+	_, ok = fx.Altitude()
+	assert.False(t, ok)
+
+	now := time.Now()
+	fx = NewFix(NewPoint(1, 2), now)
+	assert.Equal(t, NewPoint(1, 2), fx.Point())
+	assert.Equal(t, now, fx.Time())
+	_, ok = fx.Accuracy()
+	assert.False(t, ok)
+
+	fx = NewDetailedFix(NewPoint(1, 2), now, 5, math.NaN())
+	accuracy, ok := fx.Accuracy()
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, accuracy)
+	_, ok = fx.Altitude()
+	assert.False(t, ok)
+}
+
+func TestTrack_Nil(t *testing.T) {
+
+	var tr *track
+	var tk Track = tr
+
+	assert.Nil(t, tk.Fixes())
+	assert.Equal(t, 0.0, tk.Distance())
+	assert.Equal(t, time.Duration(0), tk.Duration())
+	assert.Nil(t, tk.Speeds())
+	assert.Nil(t, tk.Headings())
+	assert.Equal(t, time.Duration(0), tk.MovingTime(1))
+	assert.Equal(t, time.Duration(0), tk.StoppedTime(1))
+	assert.Nil(t, tk.StayPoints(1, time.Minute))
+	assert.Nil(t, tk.PointAt(time.Now()))
+
+	var s *stayPoint
+	var sp StayPoint = s
+
+	assert.Nil(t, sp.Center())
+	assert.True(t, sp.Arrival().IsZero())
+	assert.True(t, sp.Departure().IsZero())
+}
+
+func TestTrack(t *testing.T) {
+
+	start := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+	degree := GetDistance(NewPoint(0, 0), NewPoint(0, 1))
+
+	tk := NewTrack([]Fix{
+		NewFix(NewPoint(0, 1), start.Add(time.Hour)),
+		nil,
+		NewFix(NewPoint(0, 0), start),
+		NewFix(NewPoint(1, 1), start.Add(2*time.Hour)),
+		NewFix(NewPoint(1, 1), start.Add(3*time.Hour)),
+		NewFix(nil, start),
+	})
+
+	assert.Len(t, tk.Fixes(), 4)
+	assert.Equal(t, NewPoint(0, 0), tk.Fixes()[0].Point())
+	assert.InDelta(t, 2*degree, tk.Distance(), 1e-6)
+	assert.Equal(t, 3*time.Hour, tk.Duration())
+
+	speeds := tk.Speeds()
+	assert.Len(t, speeds, 3)
+	assert.InDelta(t, degree, speeds[0], 1e-6)
+	assert.InDelta(t, degree, speeds[1], 1e-6)
+	assert.Equal(t, 0.0, speeds[2])
+
+	headings := tk.Headings()
+	assert.InDelta(t, 90, headings[0], 1e-6)
+	assert.InDelta(t, 0, headings[1], 1e-6)
+	assert.True(t, math.IsNaN(headings[2]))
+
+	assert.Equal(t, 2*time.Hour, tk.MovingTime(1))
+	assert.Equal(t, time.Hour, tk.StoppedTime(1))
+
+	p := tk.PointAt(start.Add(30 * time.Minute))
+	assert.InDelta(t, 0, p.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 0.5, p.Longitude(), DecimalPrecision)
+	assert.Equal(t, NewPoint(0, 1), tk.PointAt(start.Add(time.Hour)))
+	assert.Equal(t, NewPoint(1, 1), tk.PointAt(start.Add(3*time.Hour)))
+	assert.Nil(t, tk.PointAt(start.Add(-time.Second)))
+	assert.Nil(t, tk.PointAt(start.Add(4*time.Hour)))
+}
+
+func TestTrack_AcrossAntimeridian(t *testing.T) {
+
+	start := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+	tk := NewTrack([]Fix{NewFix(NewPoint(0, 179.5), start), NewFix(NewPoint(0, -179.5), start.Add(time.Hour))})
+
+	assert.InDelta(t, GetDistance(NewPoint(0, 0), NewPoint(0, 1)), tk.Distance(), 1e-6)
+	assert.InDelta(t, 90, tk.Headings()[0], 1e-6)
+
+	p := tk.PointAt(start.Add(30 * time.Minute))
+	assert.InDelta(t, 180, math.Abs(p.Longitude()), DecimalPrecision)
+
+	// At the pole, the heading is taken from the Prime Meridian.
+	tk = NewTrack([]Fix{NewFix(NewPoint(90, 0), start), NewFix(NewPoint(89, 90), start.Add(time.Hour))})
+	assert.InDelta(t, 90, tk.Headings()[0], 1e-6)
+}
+
+func TestTrack_StayPoints(t *testing.T) {
+
+	start := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+	fixes := []Fix{NewFix(NewPoint(0, 0), start)}
+
+	// Driving east, stopping for 10 minutes with some GPS jitter, then driving again.
+	for i := 1; i <= 5; i++ {
+		fixes = append(fixes, NewFix(NewPoint(0, 0.01*float64(i)), start.Add(time.Duration(i)*time.Minute)))
+	}
+
+	for i := 1; i <= 10; i++ {
+		jitter := 0.0001 * float64(i%3)
+		fixes = append(fixes, NewFix(NewPoint(jitter, 0.05+jitter), start.Add(time.Duration(5+i)*time.Minute)))
+	}
+
+	for i := 1; i <= 5; i++ {
+		fixes = append(fixes, NewFix(NewPoint(0, 0.05+0.01*float64(i)), start.Add(time.Duration(15+i)*time.Minute)))
+	}
+
+	stays := NewTrack(fixes).StayPoints(0.1, 5*time.Minute)
+	assert.Len(t, stays, 1)
+	assert.Equal(t, start.Add(5*time.Minute), stays[0].Arrival())
+	assert.Equal(t, start.Add(15*time.Minute), stays[0].Departure())
+	assert.InDelta(t, 0.05, stays[0].Center().Longitude(), 0.001)
+
+	first, last := stays[0].Indices()
+	assert.Equal(t, 5, first)
+	assert.Equal(t, 15, last)
+
+	assert.Empty(t, NewTrack(fixes).StayPoints(0.1, 20*time.Minute))
+}
@@ -57,11 +57,13 @@ var borders = map[Direction][]string{
 }
 
 // GetDistance returns the distance in kilometers between two given geo-location points using haversine formula.
+// The longitude difference is wrapped into the range of -180 to 180 degrees first, so it's always taken the short
+// way around, and points on both sides of the antimeridian are close.
 func GetDistance(p1 Point, p2 Point) float64 {
 	lat1 := p1.Latitude() * DegToRad
 	lat2 := p2.Latitude() * DegToRad
 	dLat := math.Abs(lat2 - lat1)
-	dLng := math.Abs((p2.Longitude() - p1.Longitude()) * DegToRad)
+	dLng := math.Abs(math.Remainder(p2.Longitude()-p1.Longitude(), TotalLongitude) * DegToRad)
 	a := math.Pow(math.Sin(dLat/2), 2) + math.Pow(math.Sin(dLng/2), 2)*math.Cos(lat1)*math.Cos(lat2)
 	c := 2 * math.Asin(math.Sqrt(a))
 	return EarthRadiusInKM * c
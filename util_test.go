@@ -32,6 +32,8 @@ func TestGetDistance(t *testing.T) {
 	assert.InDelta(t, 111.2, GetDistance(NewPoint(1, 0), NewPoint(0, 0)), tolerance)
 	assert.InDelta(t, 855.7, GetDistance(NewPoint(50.432356, 0.873793), NewPoint(58.124521, 0.735753)), tolerance)
 	assert.InDelta(t, 1553, GetDistance(NewPoint(50.432356, 83.873793), NewPoint(58.124521, 63.735753)), tolerance)
+	assert.InDelta(t, 22.2, GetDistance(NewPoint(0, 179.9), NewPoint(0, -179.9)), tolerance)
+	assert.InDelta(t, 22.2, GetDistance(NewPoint(0, -179.9), NewPoint(0, 179.9)), tolerance)
 }
 
 func TestGetNeighbour(t *testing.T) {
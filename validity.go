@@ -0,0 +1,579 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ProblemKind represents the kind of a problem found in a polygon.
+type ProblemKind byte
+
+const (
+	// RingNotClosed means the first point of a ring is not repeated at its end.
+	RingNotClosed ProblemKind = iota + 1
+	// TooFewPoints means a ring has less than three distinct points, so it doesn't bound any area.
+	TooFewPoints
+	// DuplicateVertex means a ring goes through the same point more than once.
+	DuplicateVertex
+	// Spike means a ring turns back on itself at a vertex, going back along the edge leading to it.
+	Spike
+	// SelfIntersection means an edge of a ring crosses another edge of the same ring or of another ring.
+	SelfIntersection
+	// HoleOutsideShell means a hole has points outside the area bounded by the shell.
+	HoleOutsideShell
+	// WrongOrientation means the shell is not counter clockwise or a hole is not clockwise.
+	WrongOrientation
+)
+
+// spikeAngle is the angle in radians below which the two edges meeting at a vertex are considered overlapping.
+const spikeAngle = 1e-6
+
+var problemKindNames = map[ProblemKind]string{
+	RingNotClosed:    "ring not closed",
+	TooFewPoints:     "too few points",
+	DuplicateVertex:  "duplicate vertex",
+	Spike:            "spike",
+	SelfIntersection: "self intersection",
+	HoleOutsideShell: "hole outside shell",
+	WrongOrientation: "wrong orientation",
+}
+
+func (k ProblemKind) String() string {
+	if name, ok := problemKindNames[k]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Problem represents a problem found in a polygon, it's an error describing the problem and its location.
+type Problem interface {
+	error
+	// Kind returns the kind of the problem.
+	Kind() ProblemKind
+	// Ring returns the index of the ring having the problem, which is zero for the shell and starts at one for the
+	// holes.
+	Ring() int
+	// Vertex returns the index of the point in the ring where the problem is, or where the edge having the problem
+	// starts.
+	Vertex() int
+	// Location returns the point where the problem is.
+	Location() Point
+}
+
+type problem struct {
+	kind     ProblemKind
+	ring     int
+	vertex   int
+	location Point
+}
+
+func (p *problem) Error() string {
+	return fmt.Sprintf("%v at ring %v vertex %v %v", p.Kind(), p.Ring(), p.Vertex(), p.Location())
+}
+
+func (p *problem) Kind() ProblemKind {
+	if p != nil {
+		return p.kind
+	}
+	return 0
+}
+
+func (p *problem) Ring() int {
+	if p != nil {
+		return p.ring
+	}
+	return 0
+}
+
+func (p *problem) Vertex() int {
+	if p != nil {
+		return p.vertex
+	}
+	return 0
+}
+
+func (p *problem) Location() Point {
+	if p != nil {
+		return p.location
+	}
+	return nil
+}
+
+// samePoint reports whether the two given geo-location points have the same latlng values.
+func samePoint(p1, p2 Point) bool {
+	return p1.Latitude() == p2.Latitude() && p1.Longitude() == p2.Longitude()
+}
+
+// vertex is a ring point along with its index in the ring it was taken from.
+type vertex struct {
+	point  Point
+	vector vector
+	index  int
+}
+
+// getDistinctVertices returns the vertices of the given ring, leaving out nil points, consecutive repeated points
+// and the closing point.
+func getDistinctVertices(points []Point) []vertex {
+
+	vertices := []vertex{}
+
+	for i, p := range points {
+		if p == nil {
+			continue
+		}
+
+		if n := len(vertices); n > 0 && samePoint(vertices[n-1].point, p) {
+			continue
+		}
+
+		vertices = append(vertices, vertex{point: p, vector: toVector(p), index: i})
+	}
+
+	if n := len(vertices); n > 1 && samePoint(vertices[0].point, vertices[n-1].point) {
+		vertices = vertices[:n-1]
+	}
+
+	return vertices
+}
+
+// isSpike reports whether the ring turns back on itself at b, coming from a and going to c.
+func isSpike(a, b, c vector) bool {
+	return direction(b, a).angle(direction(b, c)) < spikeAngle
+}
+
+// ValidatePolygon checks the given polygon and returns all the problems found in it, a polygon having no problems
+// is a valid one.
+// The shell is expected to be counter clockwise and the holes to be clockwise, as suggested by the GeoJSON
+// specification, and all the rings are expected to be closed.
+func ValidatePolygon(p Polygon) []Problem {
+
+	problems := []Problem{}
+
+	if p == nil {
+		return problems
+	}
+
+	report := func(kind ProblemKind, ring int, index int, location Point) {
+		problems = append(problems, &problem{kind: kind, ring: ring, vertex: index, location: location})
+	}
+
+	rings := append([][]Point{p.Shell()}, p.Holes()...)
+	valid := make([][]vertex, len(rings))
+
+	for r, points := range rings {
+		vertices := getDistinctVertices(points)
+
+		if last := len(points) - 1; last >= 0 && points[0] != nil && points[last] != nil &&
+			!samePoint(points[0], points[last]) {
+			report(RingNotClosed, r, last, points[last])
+		}
+
+		seen := make(map[[2]float64]bool)
+
+		for i, p := range points {
+			if p == nil {
+				continue
+			}
+
+			key := [2]float64{p.Latitude(), p.Longitude()}
+
+			if seen[key] && !(i == len(points)-1 && samePoint(p, points[0])) {
+				report(DuplicateVertex, r, i, p)
+			}
+
+			seen[key] = true
+		}
+
+		if len(vertices) < 3 {
+			report(TooFewPoints, r, 0, nil)
+			continue
+		}
+
+		n := len(vertices)
+
+		for i, v := range vertices {
+			if isSpike(vertices[(i+n-1)%n].vector, v.vector, vertices[(i+1)%n].vector) {
+				report(Spike, r, v.index, v.point)
+			}
+		}
+
+		area := getSignedArea(getRingVectorsOf(getVectors(vertices)))
+
+		if (r == 0 && area < 0) || (r > 0 && area > 0) {
+			report(WrongOrientation, r, 0, vertices[0].point)
+		}
+
+		valid[r] = vertices
+	}
+
+	type edge struct {
+		ring, position int
+	}
+
+	edges := []edge{}
+
+	for r, vertices := range valid {
+		for i := range vertices {
+			edges = append(edges, edge{r, i})
+		}
+	}
+
+	ends := func(e edge) (vector, vector) {
+		vertices := valid[e.ring]
+		return vertices[e.position].vector, vertices[(e.position+1)%len(vertices)].vector
+	}
+
+	for i, e := range edges {
+		a, b := ends(e)
+
+		for _, f := range edges[i+1:] {
+			if f.ring == e.ring {
+				n := len(valid[e.ring])
+
+				// Adjacent edges always meet at their shared vertex.
+				if f.position == e.position+1 || (e.position == 0 && f.position == n-1) {
+					continue
+				}
+			}
+
+			c, d := ends(f)
+
+			if x, ok := arcsIntersection(a, b, c, d); ok {
+				report(SelfIntersection, e.ring, valid[e.ring][e.position].index, x.toPoint())
+			}
+		}
+	}
+
+	if len(valid[0]) > 0 {
+		shell := newRing(p.Shell())
+
+		for r, vertices := range valid[1:] {
+			for _, v := range vertices {
+				if !shell.contains(v.vector) {
+					report(HoleOutsideShell, r+1, v.index, v.point)
+					break
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+func getVectors(vertices []vertex) []vector {
+
+	vectors := make([]vector, len(vertices))
+
+	for i, v := range vertices {
+		vectors[i] = v.vector
+	}
+
+	return vectors
+}
+
+// removeSpikes returns the given vertices after removing the ones where the ring turns back on itself,
+// repeating until no spikes are left, since removing a spike may reveal another one.
+func removeSpikes(vertices []vertex) []vertex {
+
+	for removed := true; removed && len(vertices) >= 3; {
+		removed = false
+		n := len(vertices)
+
+		for i := 0; i < n; i++ {
+			if isSpike(vertices[(i+n-1)%n].vector, vertices[i].vector, vertices[(i+1)%n].vector) {
+				vertices = append(vertices[:i:i], vertices[i+1:]...)
+				removed = true
+				break
+			}
+		}
+
+		// Removing a spike tip may leave two equal vertices next to each other.
+		if removed {
+			distinct := []vertex{}
+
+			for _, v := range vertices {
+				if n := len(distinct); n > 0 && samePoint(distinct[n-1].point, v.point) {
+					continue
+				}
+				distinct = append(distinct, v)
+			}
+
+			if n := len(distinct); n > 1 && samePoint(distinct[0].point, distinct[n-1].point) {
+				distinct = distinct[:n-1]
+			}
+
+			vertices = distinct
+		}
+	}
+
+	return vertices
+}
+
+// splitRing returns the simple loops the given ring is made of, by adding a vertex at each of its self
+// intersections, then splitting it at each vertex it goes through more than once.
+// The loops are open, not having their first vertex repeated at the end.
+func splitRing(vertices []vertex) [][]vertex {
+
+	n := len(vertices)
+
+	if n < 3 {
+		return nil
+	}
+
+	type node struct {
+		fraction float64
+		vertex   vertex
+	}
+
+	nodes := make([][]node, n)
+
+	for i := 0; i < n; i++ {
+		a, b := vertices[i], vertices[(i+1)%n]
+
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+
+			c, d := vertices[j], vertices[(j+1)%n]
+			x, ok := arcsIntersection(a.vector, b.vector, c.vector, d.vector)
+
+			if !ok {
+				continue
+			}
+
+			// Snapping to the existing vertices, so that touching rings split at the shared vertex.
+			v := vertex{point: x.toPoint(), vector: x, index: -1}
+
+			for _, end := range []vertex{a, b, c, d} {
+				if end.vector.angle(x) < epsilon {
+					v = end
+				}
+			}
+
+			_, fi := closestOnArc(x, a.vector, b.vector)
+			_, fj := closestOnArc(x, c.vector, d.vector)
+			nodes[i] = append(nodes[i], node{fi, v})
+			nodes[j] = append(nodes[j], node{fj, v})
+		}
+	}
+
+	noded := []vertex{}
+
+	for i, v := range vertices {
+		noded = append(noded, v)
+		sort.Slice(nodes[i], func(a, b int) bool { return nodes[i][a].fraction < nodes[i][b].fraction })
+
+		for _, nd := range nodes[i] {
+			if !samePoint(noded[len(noded)-1].point, nd.vertex.point) && !samePoint(vertices[(i+1)%n].point, nd.vertex.point) {
+				noded = append(noded, nd.vertex)
+			}
+		}
+	}
+
+	loops := [][]vertex{}
+	path := []vertex{}
+	positions := make(map[[2]float64]int)
+
+	for _, v := range append(noded, noded[0]) {
+		key := [2]float64{v.point.Latitude(), v.point.Longitude()}
+
+		if at, ok := positions[key]; ok {
+			loops = append(loops, append([]vertex{}, path[at:]...))
+
+			for _, u := range path[at+1:] {
+				delete(positions, [2]float64{u.point.Latitude(), u.point.Longitude()})
+			}
+
+			path = path[:at+1]
+			continue
+		}
+
+		positions[key] = len(path)
+		path = append(path, v)
+	}
+
+	result := [][]vertex{}
+
+	for _, loop := range loops {
+		loop = removeSpikes(loop)
+
+		if len(loop) >= 3 && math.Abs(getSignedArea(getVectors(loop))) > epsilon*epsilon {
+			result = append(result, loop)
+		}
+	}
+
+	return result
+}
+
+// toRing returns the points of the given loop as a closed ring, having the given orientation.
+func toRing(loop []vertex, counterClockwise bool) []Point {
+
+	points := make([]Point, 0, len(loop)+1)
+
+	for _, v := range loop {
+		points = append(points, v.point)
+	}
+
+	if (getSignedArea(getVectors(loop)) > 0) != counterClockwise {
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	}
+
+	return append(points, points[0])
+}
+
+// loopInside reports whether the loop a is inside the area bounded by the loop b, having a vertex of a that's
+// not shared with b inside it.
+func loopInside(a, b []vertex, bRing *ring) bool {
+
+	shared := make(map[[2]float64]bool)
+
+	for _, v := range b {
+		shared[[2]float64{v.point.Latitude(), v.point.Longitude()}] = true
+	}
+
+	for _, v := range a {
+		if !shared[[2]float64{v.point.Latitude(), v.point.Longitude()}] {
+			return bRing.contains(v.vector)
+		}
+	}
+
+	return false
+}
+
+// loopsCross reports whether any edge of the loop a crosses any edge of the loop b.
+func loopsCross(a, b []vertex) bool {
+
+	for i := range a {
+		for j := range b {
+			p, q := a[i].vector, a[(i+1)%len(a)].vector
+			r, s := b[j].vector, b[(j+1)%len(b)].vector
+
+			if x, ok := arcsIntersection(p, q, r, s); ok {
+				// Touching at a shared vertex is not crossing.
+				if x.angle(p) >= epsilon && x.angle(q) >= epsilon && x.angle(r) >= epsilon && x.angle(s) >= epsilon {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// RepairPolygon returns a valid version of the given polygon, it closes the rings, removes the duplicate vertices
+// and the spikes, and makes the shell counter clockwise and the holes clockwise.
+// A self intersecting shell is split into the simple polygons it's made of, following the even-odd rule, where
+// the loops enclosed by an odd number of other loops become holes, which is why the result is a MultiPolygon.
+// Holes are split the same way, and the parts of them that are not inside a single resulting polygon,
+// or that cross other holes, are left out.
+func RepairPolygon(p Polygon) MultiPolygon {
+
+	if p == nil {
+		return NewMultiPolygon()
+	}
+
+	type part struct {
+		shell     []vertex
+		shellRing *ring
+		holes     [][]vertex
+	}
+
+	loops := splitRing(removeSpikes(getDistinctVertices(p.Shell())))
+	rings := make([]*ring, len(loops))
+
+	for i, loop := range loops {
+		rings[i] = newRing(toRing(loop, true))
+	}
+
+	parts := []*part{}
+	owners := make([]*part, len(loops))
+	depths := make([]int, len(loops))
+
+	// Following the even-odd rule, the loops are sorted from the outer to the inner ones, so that each hole finds
+	// the part of its enclosing loop.
+	for i := range loops {
+		for j := range loops {
+			if i != j && loopInside(loops[i], loops[j], rings[j]) {
+				depths[i]++
+			}
+		}
+	}
+
+	order := makeRange(len(loops))
+	sort.SliceStable(order, func(a, b int) bool { return depths[order[a]] < depths[order[b]] })
+
+	for _, i := range order {
+		if depths[i]%2 == 0 {
+			owners[i] = &part{shell: loops[i], shellRing: rings[i]}
+			parts = append(parts, owners[i])
+			continue
+		}
+
+		for _, j := range order {
+			if depths[j] == depths[i]-1 && owners[j] != nil && loopInside(loops[i], loops[j], rings[j]) {
+				owners[j].holes = append(owners[j].holes, loops[i])
+				break
+			}
+		}
+	}
+
+	for _, h := range p.Holes() {
+		for _, hole := range splitRing(removeSpikes(getDistinctVertices(h))) {
+			for _, pt := range parts {
+				if !loopInside(hole, pt.shell, pt.shellRing) || loopsCross(hole, pt.shell) {
+					continue
+				}
+
+				valid := true
+
+				for _, other := range pt.holes {
+					if loopsCross(hole, other) || loopInside(hole, other, newRing(toRing(other, true))) ||
+						loopInside(other, hole, newRing(toRing(hole, true))) {
+						valid = false
+						break
+					}
+				}
+
+				if valid {
+					pt.holes = append(pt.holes, hole)
+				}
+
+				break
+			}
+		}
+	}
+
+	polygons := []Polygon{}
+
+	for _, pt := range parts {
+		holes := [][]Point{}
+
+		for _, h := range pt.holes {
+			holes = append(holes, toRing(h, false))
+		}
+
+		polygons = append(polygons, NewPolygon(toRing(pt.shell, true), holes...))
+	}
+
+	return NewMultiPolygon(polygons...)
+}
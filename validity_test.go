@@ -0,0 +1,153 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getProblemKinds(problems []Problem) []ProblemKind {
+	kinds := []ProblemKind{}
+	for _, p := range problems {
+		kinds = append(kinds, p.Kind())
+	}
+	return kinds
+}
+
+func TestProblem(t *testing.T) {
+
+	var p *problem
+	var pr Problem = p
+
+	assert.Equal(t, ProblemKind(0), pr.Kind())
+	assert.Equal(t, 0, pr.Ring())
+	assert.Equal(t, 0, pr.Vertex())
+	assert.Nil(t, pr.Location())
+
+	pr = &problem{kind: Spike, ring: 1, vertex: 2, location: NewPoint(1, 2)}
+	assert.Equal(t, "spike at ring 1 vertex 2 (1, 2)", pr.Error())
+	assert.Equal(t, "unknown", ProblemKind(0).String())
+}
+
+func TestValidatePolygon(t *testing.T) {
+
+	assert.Empty(t, ValidatePolygon(nil))
+	assert.Empty(t, ValidatePolygon(NewPolygon(getSquare(0, 0, 10), reverseRing(getSquare(4, 4, 2)))))
+
+	problems := ValidatePolygon(NewPolygon(getSquare(0, 0, 10)[:4]))
+	assert.Equal(t, []ProblemKind{RingNotClosed}, getProblemKinds(problems))
+	assert.Equal(t, 3, problems[0].Vertex())
+
+	problems = ValidatePolygon(NewPolygon(reverseRing(getSquare(0, 0, 10)), getSquare(4, 4, 2)))
+	assert.Equal(t, []ProblemKind{WrongOrientation, WrongOrientation}, getProblemKinds(problems))
+	assert.Equal(t, 1, problems[1].Ring())
+
+	problems = ValidatePolygon(NewPolygon(getSquare(0, 0, 10)[:2]))
+	assert.Equal(t, []ProblemKind{RingNotClosed, TooFewPoints}, getProblemKinds(problems))
+
+	shell := []Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0), NewPoint(0, 0)}
+	problems = ValidatePolygon(NewPolygon(shell))
+	assert.Equal(t, []ProblemKind{DuplicateVertex}, getProblemKinds(problems))
+	assert.Equal(t, 2, problems[0].Vertex())
+
+	// A spike going out of the square and back along the same way.
+	shell = []Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(5, 10), NewPoint(5, 15), NewPoint(5, 10), NewPoint(10, 10), NewPoint(10, 0), NewPoint(0, 0)}
+	problems = ValidatePolygon(NewPolygon(shell))
+	assert.Contains(t, getProblemKinds(problems), Spike)
+	assert.Contains(t, getProblemKinds(problems), DuplicateVertex)
+
+	for _, p := range problems {
+		if p.Kind() == Spike {
+			assert.Equal(t, 3, p.Vertex())
+		}
+	}
+
+	// A bow tie crossing itself at (5, 5).
+	bowtie := []Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 0), NewPoint(10, 10), NewPoint(0, 0)}
+	problems = ValidatePolygon(NewPolygon(bowtie))
+	assert.Contains(t, getProblemKinds(problems), SelfIntersection)
+
+	for _, p := range problems {
+		if p.Kind() == SelfIntersection {
+			assert.Equal(t, 1, p.Vertex())
+			assert.InDelta(t, 5, p.Location().Longitude(), DecimalPrecision)
+		}
+	}
+
+	// A hole sticking out of the shell.
+	problems = ValidatePolygon(NewPolygon(getSquare(0, 0, 10), reverseRing(getSquare(8, 8, 4))))
+	kinds := getProblemKinds(problems)
+	assert.Contains(t, kinds, SelfIntersection)
+	assert.Contains(t, kinds, HoleOutsideShell)
+
+	problems = ValidatePolygon(NewPolygon(getSquare(0, 0, 10), reverseRing(getSquare(20, 20, 1))))
+	assert.Equal(t, []ProblemKind{HoleOutsideShell}, getProblemKinds(problems))
+	assert.Equal(t, 1, problems[0].Ring())
+}
+
+func TestRepairPolygon(t *testing.T) {
+
+	assert.Empty(t, RepairPolygon(nil).Polygons())
+	assert.Empty(t, RepairPolygon(NewPolygon(getSquare(0, 0, 1)[:2])).Polygons())
+
+	// Wrong orientation, not closed, with a duplicate vertex and a spike.
+	shell := []Point{NewPoint(10, 0), NewPoint(10, 10), NewPoint(10, 10), NewPoint(5, 10), NewPoint(5, 15), NewPoint(5, 10), NewPoint(0, 10), NewPoint(0, 0)}
+	m := RepairPolygon(NewPolygon(shell, getSquare(4, 4, 2)))
+
+	assert.Len(t, m.Polygons(), 1)
+	assert.Empty(t, ValidatePolygon(m.Polygons()[0]))
+	assert.Len(t, m.Polygons()[0].Shell(), 6)
+	assert.Len(t, m.Polygons()[0].Holes(), 1)
+	assert.InDelta(t, NewPolygon(getSquare(0, 0, 10), getSquare(4, 4, 2)).Area(), m.Area(), 1e-6)
+
+	// A bow tie becomes two triangles.
+	bowtie := []Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 0), NewPoint(10, 10), NewPoint(0, 0)}
+	m = RepairPolygon(NewPolygon(bowtie))
+
+	assert.Len(t, m.Polygons(), 2)
+	for _, p := range m.Polygons() {
+		assert.Empty(t, ValidatePolygon(p))
+		assert.Len(t, p.Shell(), 4)
+	}
+	assert.True(t, m.Contains(NewPoint(2, 5)))
+	assert.True(t, m.Contains(NewPoint(8, 5)))
+	assert.False(t, m.Contains(NewPoint(5, 2)))
+
+	// Holes outside the shell are left out.
+	m = RepairPolygon(NewPolygon(getSquare(0, 0, 10), getSquare(20, 20, 1), getSquare(8, 8, 4), getSquare(1, 1, 1)))
+	assert.Len(t, m.Polygons(), 1)
+	assert.Len(t, m.Polygons()[0].Holes(), 1)
+	assert.Empty(t, ValidatePolygon(m.Polygons()[0]))
+}
+
+func TestRepairPolygon_EvenOdd(t *testing.T) {
+
+	// A ring going around a square, then around an inner square through a shared vertex, which leaves the inner
+	// square as a hole following the even-odd rule.
+	shell := []Point{
+		NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0), NewPoint(0, 0),
+		NewPoint(2, 2), NewPoint(2, 4), NewPoint(4, 4), NewPoint(4, 2), NewPoint(2, 2), NewPoint(0, 0),
+	}
+
+	m := RepairPolygon(NewPolygon(shell))
+	assert.Len(t, m.Polygons(), 1)
+	assert.Len(t, m.Polygons()[0].Holes(), 1)
+	assert.True(t, m.Contains(NewPoint(1, 1)))
+	assert.False(t, m.Contains(NewPoint(3, 3)))
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+)
+
+const (
+	// radians is the exact conversion factor of angle from degrees to radians.
+	// Calculations on unit vectors have to convert back and forth between latlng values and vectors,
+	// which only holds near the poles when the conversion is exact.
+	radians float64 = math.Pi / 180.0
+	// epsilon is the angular tolerance in radians used when comparing vectors, it is about 6mm on the surface.
+	epsilon float64 = 1e-9
+)
+
+// vector is a 3D cartesian vector, mostly used to represent a geo-location point as a unit vector
+// starting at the center of earth, where the z axis points at the north pole and the x axis points at
+// the intersection of the Equator and the Prime Meridian.
+type vector struct {
+	x, y, z float64
+}
+
+// toVector returns the unit vector representing the given geo-location point.
+func toVector(p Point) vector {
+	lat := p.Latitude() * radians
+	lng := p.Longitude() * radians
+	return vector{math.Cos(lat) * math.Cos(lng), math.Cos(lat) * math.Sin(lng), math.Sin(lat)}
+}
+
+// toPoint returns the geo-location point that the vector is pointing at, the vector doesn't have to be a unit vector.
+func (v vector) toPoint() Point {
+	lat := math.Atan2(v.z, math.Hypot(v.x, v.y))
+	lng := math.Atan2(v.y, v.x)
+	return NewPoint(lat/radians, lng/radians)
+}
+
+func (v vector) add(w vector) vector {
+	return vector{v.x + w.x, v.y + w.y, v.z + w.z}
+}
+
+func (v vector) sub(w vector) vector {
+	return vector{v.x - w.x, v.y - w.y, v.z - w.z}
+}
+
+func (v vector) scale(f float64) vector {
+	return vector{v.x * f, v.y * f, v.z * f}
+}
+
+func (v vector) dot(w vector) float64 {
+	return v.x*w.x + v.y*w.y + v.z*w.z
+}
+
+func (v vector) cross(w vector) vector {
+	return vector{v.y*w.z - v.z*w.y, v.z*w.x - v.x*w.z, v.x*w.y - v.y*w.x}
+}
+
+func (v vector) norm() float64 {
+	return math.Sqrt(v.dot(v))
+}
+
+// normalize returns the unit vector having the same direction, or the zero vector if v is the zero vector.
+func (v vector) normalize() vector {
+	n := v.norm()
+	if n == 0 {
+		return v
+	}
+	return v.scale(1 / n)
+}
+
+// angle returns the angle in radians between the two vectors, it is numerically stable for both small and
+// large angles unlike the plain arc cosine of the dot product.
+func (v vector) angle(w vector) float64 {
+	return math.Atan2(v.cross(w).norm(), v.dot(w))
+}
+
+// angleToKM converts a central angle in radians to the distance in kilometers on the surface of earth.
+func angleToKM(angle float64) float64 {
+	return angle * EarthRadiusInKM
+}
+
+// kmToAngle converts a distance in kilometers on the surface of earth to a central angle in radians.
+func kmToAngle(km float64) float64 {
+	return km / EarthRadiusInKM
+}
+
+// gnomonic is a gnomonic projection of the sphere onto the plane tangent at a given center, it maps great
+// circles to straight lines, which makes it suitable to run planar algorithms on points in the same hemisphere
+// as the center. The axes are chosen so that counter clockwise on the plane is counter clockwise on earth
+// seen from above.
+type gnomonic struct {
+	center vector
+	east   vector
+	north  vector
+}
+
+func newGnomonic(center vector) gnomonic {
+	east := vector{0, 0, 1}.cross(center)
+
+	if east.norm() < epsilon {
+		// The center is at one of the poles, any direction would do.
+		east = vector{0, 1, 0}
+	}
+
+	east = east.normalize()
+
+	return gnomonic{center: center, east: east, north: center.cross(east)}
+}
+
+// project returns the plane coordinates of the given unit vector, the vector has to be in the same open
+// hemisphere as the projection center.
+func (g gnomonic) project(v vector) (float64, float64) {
+	d := v.dot(g.center)
+	return v.dot(g.east) / d, v.dot(g.north) / d
+}
+
+// unproject returns the unit vector of the given plane coordinates.
+func (g gnomonic) unproject(x, y float64) vector {
+	return g.center.add(g.east.scale(x)).add(g.north.scale(y)).normalize()
+}
+
+// closestOnArc returns the closest vector to v on the great circle arc going from a to b, along with the fraction
+// of the arc length from a to that closest vector.
+// If the arc is degenerate, because its ends are the same or antipodal, a is returned.
+func closestOnArc(v, a, b vector) (vector, float64) {
+
+	n := a.cross(b)
+
+	if n.norm() < epsilon*epsilon {
+		return a, 0
+	}
+
+	n = n.normalize()
+	p := v.sub(n.scale(v.dot(n)))
+
+	// The projection of v on the great circle plane lands between the arc ends only if it's on the left of
+	// the plane through a and n and on the right of the plane through b and n.
+	if p.norm() >= epsilon*epsilon {
+		p = p.normalize()
+
+		if a.cross(p).dot(n) >= 0 && p.cross(b).dot(n) >= 0 {
+			return p, a.angle(p) / a.angle(b)
+		}
+	}
+
+	if v.angle(a) <= v.angle(b) {
+		return a, 0
+	}
+
+	return b, 1
+}
+
+// triangleArea returns the area of the spherical triangle of the given unit vectors on the unit sphere,
+// which is its spherical excess in radians.
+func triangleArea(a, b, c vector) float64 {
+	return 2 * math.Abs(math.Atan2(a.dot(b.cross(c)), 1+a.dot(b)+b.dot(c)+c.dot(a)))
+}
+
+// interpolate returns the unit vector at the given fraction along the great circle arc going from a to b.
+func interpolate(a, b vector, fraction float64) vector {
+
+	angle := a.angle(b)
+
+	if angle < epsilon {
+		return a.add(b.sub(a).scale(fraction)).normalize()
+	}
+
+	sin := math.Sin(angle)
+
+	return a.scale(math.Sin((1-fraction)*angle) / sin).add(b.scale(math.Sin(fraction*angle) / sin)).normalize()
+}
+
+// arcsCross reports whether the great circle arc going from a to b crosses the one going from c to d, both arcs
+// have to be shorter than half a great circle.
+// Vectors lying exactly on the great circle of the other arc are considered on its left side, so that a line
+// going exactly through a vertex shared by two arcs crosses only one of them.
+func arcsCross(a, b, c, d vector) bool {
+
+	n := a.cross(b)
+
+	if (n.dot(c) >= 0) == (n.dot(d) >= 0) {
+		return false
+	}
+
+	m := c.cross(d)
+
+	if (m.dot(a) >= 0) == (m.dot(b) >= 0) {
+		return false
+	}
+
+	// Both great circles meet at x and its antipode, each arc contains only one of them, which is the one
+	// on the side of its midpoint.
+	x := n.cross(m)
+
+	return (x.dot(a.add(b)) > 0) == (x.dot(c.add(d)) > 0)
+}
+
+// perpendicular returns a unit vector perpendicular to the given one.
+func (v vector) perpendicular() vector {
+
+	axis := vector{1, 0, 0}
+
+	if math.Abs(v.x) > 0.5 {
+		axis = vector{0, 1, 0}
+	}
+
+	return v.cross(axis).normalize()
+}
+
+// arcsIntersection returns the vector where the great circle arc going from a to b crosses the one going from
+// c to d, if they cross, both arcs have to be shorter than half a great circle.
+func arcsIntersection(a, b, c, d vector) (vector, bool) {
+
+	if !arcsCross(a, b, c, d) {
+		return vector{}, false
+	}
+
+	x := a.cross(b).cross(c.cross(d)).normalize()
+
+	if x.dot(a.add(b)) < 0 {
+		x = x.scale(-1)
+	}
+
+	return x, true
+}
+
+// direction returns the unit vector tangent to the sphere at v pointing towards w along the great circle
+// through both of them.
+func direction(v, w vector) vector {
+	return w.sub(v.scale(v.dot(w))).normalize()
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVector_RoundTrip(t *testing.T) {
+	for i := 0; i < 10000; i++ {
+		p := NewPoint(rand.Float64()*180-90, rand.Float64()*360-180)
+		v := toVector(p)
+		assert.InDelta(t, 1, v.norm(), 1e-12)
+
+		q := v.toPoint()
+		assert.InDelta(t, p.Latitude(), q.Latitude(), DecimalPrecision)
+		assert.InDelta(t, 0, math.Remainder(p.Longitude()-q.Longitude(), TotalLongitude), DecimalPrecision)
+	}
+
+	p := toVector(NewPoint(90, 0)).toPoint()
+	assert.InDelta(t, 90, p.Latitude(), 0)
+	assert.InDelta(t, 0, p.Longitude(), 0)
+
+	p = toVector(NewPoint(-90, 0)).toPoint()
+	assert.InDelta(t, -90, p.Latitude(), 0)
+	assert.InDelta(t, 0, p.Longitude(), 0)
+}
+
+func TestVector_Angle(t *testing.T) {
+	a := toVector(NewPoint(0, 0))
+	b := toVector(NewPoint(0, 90))
+	c := toVector(NewPoint(90, 0))
+
+	assert.InDelta(t, math.Pi/2, a.angle(b), 1e-12)
+	assert.InDelta(t, math.Pi/2, a.angle(c), 1e-12)
+	assert.InDelta(t, math.Pi, a.angle(a.scale(-1)), 1e-12)
+	assert.InDelta(t, 0, a.angle(a), 1e-12)
+	assert.InDelta(t, 1, a.cross(b).dot(c), 1e-12)
+	assert.Equal(t, vector{}, vector{}.normalize())
+
+	p1 := NewPoint(50.432356, 83.873793)
+	p2 := NewPoint(58.124521, 63.735753)
+	assert.InDelta(t, GetDistance(p1, p2), angleToKM(toVector(p1).angle(toVector(p2))), 1)
+	assert.InDelta(t, 1, kmToAngle(angleToKM(1)), 1e-12)
+}
+
+func TestVector_ClosestOnArc(t *testing.T) {
+	a := toVector(NewPoint(0, 0))
+	b := toVector(NewPoint(0, 10))
+
+	c, f := closestOnArc(toVector(NewPoint(1, 5)), a, b)
+	assert.InDelta(t, 0.5, f, 1e-9)
+	assert.InDelta(t, 0, c.toPoint().Latitude(), DecimalPrecision)
+	assert.InDelta(t, 5, c.toPoint().Longitude(), DecimalPrecision)
+
+	c, f = closestOnArc(toVector(NewPoint(1, -5)), a, b)
+	assert.InDelta(t, 0, f, 0)
+	assert.Equal(t, a, c)
+
+	c, f = closestOnArc(toVector(NewPoint(-1, 15)), a, b)
+	assert.InDelta(t, 1, f, 0)
+	assert.Equal(t, b, c)
+
+	c, f = closestOnArc(toVector(NewPoint(1, 5)), a, a)
+	assert.InDelta(t, 0, f, 0)
+	assert.Equal(t, a, c)
+
+	// The arc between two points on the same parallel bulges towards the pole.
+	c, _ = closestOnArc(toVector(NewPoint(60, 0)), toVector(NewPoint(60, -40)), toVector(NewPoint(60, 40)))
+	assert.True(t, c.toPoint().Latitude() > 64)
+}
+
+func TestVector_TriangleArea(t *testing.T) {
+	// An octant of the sphere.
+	a := toVector(NewPoint(0, 0))
+	b := toVector(NewPoint(0, 90))
+	c := toVector(NewPoint(90, 0))
+
+	assert.InDelta(t, math.Pi/2, triangleArea(a, b, c), 1e-12)
+	assert.InDelta(t, math.Pi/2, triangleArea(c, b, a), 1e-12)
+	assert.InDelta(t, 0, triangleArea(a, a, c), 1e-12)
+}
+
+func TestVector_Interpolate(t *testing.T) {
+	a := toVector(NewPoint(0, 0))
+	b := toVector(NewPoint(0, 90))
+
+	p := interpolate(a, b, 0.5).toPoint()
+	assert.InDelta(t, 0, p.Latitude(), DecimalPrecision)
+	assert.InDelta(t, 45, p.Longitude(), DecimalPrecision)
+
+	assert.InDelta(t, 0, interpolate(a, b, 0).angle(a), 1e-12)
+	assert.InDelta(t, 0, interpolate(a, b, 1).angle(b), 1e-12)
+	assert.InDelta(t, 0, interpolate(a, a, 0.5).angle(a), 1e-12)
+}
+
+func cosDeg(d float64) float64 {
+	return math.Cos(d * radians)
+}
+
+func sinDeg(d float64) float64 {
+	return math.Sin(d * radians)
+}
+
+func TestVector_ArcsCross(t *testing.T) {
+	a := toVector(NewPoint(-1, 0))
+	b := toVector(NewPoint(1, 0))
+	c := toVector(NewPoint(0, -1))
+	d := toVector(NewPoint(0, 1))
+
+	assert.True(t, arcsCross(a, b, c, d))
+	assert.True(t, arcsCross(b, a, c, d))
+	assert.False(t, arcsCross(a, c, b, d))
+
+	// Arcs on the same pair of great circles, but around opposite intersections.
+	assert.False(t, arcsCross(a, b, c.scale(-1), d.scale(-1)))
+
+	for _, v := range []vector{a, b, c, toVector(NewPoint(90, 0))} {
+		p := v.perpendicular()
+		assert.InDelta(t, 0, p.dot(v), 1e-12)
+		assert.InDelta(t, 1, p.norm(), 1e-12)
+	}
+}
+
+func TestVector_ArcsIntersection(t *testing.T) {
+	a := toVector(NewPoint(-1, 179))
+	b := toVector(NewPoint(1, -179))
+	c := toVector(NewPoint(1, 179))
+	d := toVector(NewPoint(-1, -179))
+
+	x, ok := arcsIntersection(a, b, c, d)
+	assert.True(t, ok)
+	assert.InDelta(t, 0, x.toPoint().Latitude(), DecimalPrecision)
+	assert.InDelta(t, 180, x.toPoint().Longitude(), DecimalPrecision)
+
+	_, ok = arcsIntersection(a, c, b, d)
+	assert.False(t, ok)
+
+	north := direction(toVector(NewPoint(0, 0)), toVector(NewPoint(10, 0)))
+	assert.InDelta(t, 0, north.angle(vector{0, 0, 1}), 1e-12)
+}
@@ -0,0 +1,408 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+)
+
+// ErrInvalidVPTree is returned when unmarshaling data that is not a valid serialized vantage point tree.
+var ErrInvalidVPTree = errors.New("invalid vantage point tree")
+
+// vpTreeMagic starts the serialized vantage point trees, followed by the format version.
+const vpTreeMagic = "GVPT\x01"
+
+// VPTree is a vantage point tree, which is a metric tree indexing geo-location points under any distance function,
+// where each node splits the points around it into the nearer and the farther halves.
+// The searches are exact as long as the metric satisfies the triangle inequality, the points that can't be nearer
+// than the ones found so far are pruned using the distances to the nodes only.
+type VPTree interface {
+	NeighbourIndex
+	// Range returns the points within the given distance from the given query point, as measured by the metric
+	// of the tree, sorted by their distance, then by their index.
+	Range(query Point, radius float64) []Neighbour
+	// MarshalBinary serializes the points and the structure of the tree, but not its metric.
+	MarshalBinary() ([]byte, error)
+}
+
+// vpNode is a node of a vantage point tree, where the points of the inside subtree are no farther than inner from
+// the vantage point, and the points of the outside subtree are no nearer than outer.
+type vpNode struct {
+	index   int
+	inner   float64
+	outer   float64
+	inside  int
+	outside int
+}
+
+type vpTree struct {
+	points []Point
+	metric Metric
+	// nodes holds the nodes in pre-order, so the root is the first one and children follow their parents.
+	nodes []vpNode
+}
+
+func (t *vpTree) Points() []Point {
+	if t != nil {
+		return t.points
+	}
+	return nil
+}
+
+// build adds the nodes of the subtree of the given point indices, returning the index of its root node.
+func (t *vpTree) build(items []int) int {
+
+	if len(items) == 0 {
+		return -1
+	}
+
+	// The point farthest from an arbitrary one tends to be at the edge of the points, making a good vantage point.
+	vantage, farthest := items[0], -1.0
+
+	for _, i := range items {
+		if d := t.metric(t.points[items[0]], t.points[i]); d > farthest {
+			vantage, farthest = i, d
+		}
+	}
+
+	rest := make([]Neighbour, 0, len(items)-1)
+
+	for _, i := range items {
+		if i != vantage {
+			rest = append(rest, Neighbour{Index: i, Distance: t.metric(t.points[vantage], t.points[i])})
+		}
+	}
+
+	sort.SliceStable(rest, func(i, j int) bool { return rest[i].Distance < rest[j].Distance })
+
+	node := len(t.nodes)
+	t.nodes = append(t.nodes, vpNode{index: vantage, inside: -1, outside: -1})
+
+	if len(rest) == 0 {
+		return node
+	}
+
+	half := len(rest) / 2
+	inside := make([]int, half)
+	outside := make([]int, len(rest)-half)
+
+	for i, n := range rest {
+		if i < half {
+			inside[i] = n.Index
+		} else {
+			outside[i-half] = n.Index
+		}
+	}
+
+	if half > 0 {
+		t.nodes[node].inner = rest[half-1].Distance
+	}
+
+	t.nodes[node].outer = rest[half].Distance
+
+	in := t.build(inside)
+	out := t.build(outside)
+	t.nodes[node].inside, t.nodes[node].outside = in, out
+
+	return node
+}
+
+// search visits the points of the subtree of the given node that may be within the distance returned by the given
+// function from the query point, visiting the nearer side of each node first so that distance shrinks sooner.
+func (t *vpTree) search(node int, query Point, limit func() float64, visit func(i int, distance float64)) {
+
+	if node < 0 {
+		return
+	}
+
+	n := t.nodes[node]
+	d := t.metric(query, t.points[n.index])
+
+	visit(n.index, d)
+
+	// By the triangle inequality the inside points are at least d - inner away from the query point,
+	// and the outside points are at least outer - d away.
+	searchInside := func() {
+		if n.inside >= 0 && d-n.inner <= limit() {
+			t.search(n.inside, query, limit, visit)
+		}
+	}
+
+	searchOutside := func() {
+		if n.outside >= 0 && n.outer-d <= limit() {
+			t.search(n.outside, query, limit, visit)
+		}
+	}
+
+	if d <= (n.inner+n.outer)/2 {
+		searchInside()
+		searchOutside()
+	} else {
+		searchOutside()
+		searchInside()
+	}
+}
+
+// neighbourHeap is a max heap of the neighbours by their distance, then by their index.
+type neighbourHeap []Neighbour
+
+func (h neighbourHeap) Len() int {
+	return len(h)
+}
+
+func (h neighbourHeap) Less(i, j int) bool {
+	return nearerNeighbour(h[j], h[i])
+}
+
+func (h neighbourHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *neighbourHeap) Push(x interface{}) {
+	*h = append(*h, x.(Neighbour))
+}
+
+func (h *neighbourHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// nearerNeighbour reports whether the first neighbour comes before the second one, by their distance,
+// then by their index.
+func nearerNeighbour(a, b Neighbour) bool {
+	if a.Distance != b.Distance {
+		return a.Distance < b.Distance
+	}
+	return a.Index < b.Index
+}
+
+func sortNeighbours(neighbours []Neighbour) {
+	sort.Slice(neighbours, func(i, j int) bool { return nearerNeighbour(neighbours[i], neighbours[j]) })
+}
+
+func (t *vpTree) Nearest(query Point, k int, options NeighbourOptions) []Neighbour {
+
+	if t == nil || query == nil || k <= 0 || len(t.nodes) == 0 {
+		return []Neighbour{}
+	}
+
+	h := &neighbourHeap{}
+
+	limit := func() float64 {
+		if h.Len() == k {
+			return (*h)[0].Distance
+		}
+		if options.MaxDistance > 0 {
+			return options.MaxDistance
+		}
+		return math.Inf(1)
+	}
+
+	t.search(0, query, limit, func(i int, distance float64) {
+		if options.MaxDistance > 0 && distance > options.MaxDistance {
+			return
+		}
+
+		n := Neighbour{Index: i, Point: t.points[i], Distance: distance}
+
+		if h.Len() < k {
+			heap.Push(h, n)
+		} else if nearerNeighbour(n, (*h)[0]) {
+			(*h)[0] = n
+			heap.Fix(h, 0)
+		}
+	})
+
+	found := []Neighbour(*h)
+	sortNeighbours(found)
+
+	return found
+}
+
+func (t *vpTree) Range(query Point, radius float64) []Neighbour {
+
+	found := []Neighbour{}
+
+	if t == nil || query == nil || radius < 0 || len(t.nodes) == 0 {
+		return found
+	}
+
+	limit := func() float64 {
+		return radius
+	}
+
+	t.search(0, query, limit, func(i int, distance float64) {
+		if distance <= radius {
+			found = append(found, Neighbour{Index: i, Point: t.points[i], Distance: distance})
+		}
+	})
+
+	sortNeighbours(found)
+
+	return found
+}
+
+// vpPointRecord and vpNodeRecord are the fixed size records of the serialized points and nodes.
+type vpPointRecord struct {
+	Valid     bool
+	Latitude  float64
+	Longitude float64
+}
+
+type vpNodeRecord struct {
+	Index   uint32
+	Inner   float64
+	Outer   float64
+	Inside  int32
+	Outside int32
+}
+
+func (t *vpTree) MarshalBinary() ([]byte, error) {
+
+	buf := bytes.NewBufferString(vpTreeMagic)
+	records := []interface{}{uint32(len(t.Points()))}
+
+	for _, p := range t.Points() {
+		if p == nil {
+			records = append(records, vpPointRecord{})
+		} else {
+			records = append(records, vpPointRecord{Valid: true, Latitude: p.Latitude(), Longitude: p.Longitude()})
+		}
+	}
+
+	if t != nil {
+		records = append(records, uint32(len(t.nodes)))
+
+		for _, n := range t.nodes {
+			records = append(records, vpNodeRecord{
+				Index: uint32(n.index), Inner: n.inner, Outer: n.outer, Inside: int32(n.inside), Outside: int32(n.outside),
+			})
+		}
+	} else {
+		records = append(records, uint32(0))
+	}
+
+	for _, r := range records {
+		if err := binary.Write(buf, binary.LittleEndian, r); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// NewVPTree creates a new vantage point tree of the given geo-location points under the given metric, when it's nil
+// GetDistance is used instead.
+// The tree is built at once by splitting the points around each vantage point at the median distance from it,
+// nil points are left out of the searches.
+func NewVPTree(points []Point, metric Metric) VPTree {
+
+	if metric == nil {
+		metric = GetDistance
+	}
+
+	t := &vpTree{points: points, metric: metric}
+	items := make([]int, 0, len(points))
+
+	for i, p := range points {
+		if p != nil {
+			items = append(items, i)
+		}
+	}
+
+	t.build(items)
+
+	return t
+}
+
+// UnmarshalVPTree restores a vantage point tree serialized by its MarshalBinary method, which has to be given
+// the same metric the tree was built with, when it's nil GetDistance is used instead.
+// ErrInvalidVPTree is returned if the data is not a valid serialized tree.
+func UnmarshalVPTree(data []byte, metric Metric) (VPTree, error) {
+
+	if metric == nil {
+		metric = GetDistance
+	}
+
+	if !bytes.HasPrefix(data, []byte(vpTreeMagic)) {
+		return nil, ErrInvalidVPTree
+	}
+
+	r := bytes.NewReader(data[len(vpTreeMagic):])
+	read := func(v interface{}) bool {
+		return binary.Read(r, binary.LittleEndian, v) == nil
+	}
+
+	var count uint32
+
+	// Each point takes at least a byte, which bounds the count before allocating anything.
+	if !read(&count) || int64(count) > int64(r.Len()) {
+		return nil, ErrInvalidVPTree
+	}
+
+	t := &vpTree{points: make([]Point, count), metric: metric}
+
+	for i := range t.points {
+		var p vpPointRecord
+
+		if !read(&p) {
+			return nil, ErrInvalidVPTree
+		}
+
+		if p.Valid {
+			t.points[i] = NewPoint(p.Latitude, p.Longitude)
+		}
+	}
+
+	if !read(&count) || int64(count) > int64(r.Len()) {
+		return nil, ErrInvalidVPTree
+	}
+
+	t.nodes = make([]vpNode, count)
+
+	for i := range t.nodes {
+		var n vpNodeRecord
+
+		if !read(&n) {
+			return nil, ErrInvalidVPTree
+		}
+
+		// The children follow their parents, which rules out any cycles.
+		child := func(c int32) bool {
+			return c == -1 || int(c) > i && int(c) < len(t.nodes)
+		}
+
+		if int(n.Index) >= len(t.points) || t.points[n.Index] == nil || !child(n.Inside) || !child(n.Outside) {
+			return nil, ErrInvalidVPTree
+		}
+
+		t.nodes[i] = vpNode{index: int(n.Index), inner: n.Inner, outer: n.Outer, inside: int(n.Inside), outside: int(n.Outside)}
+	}
+
+	if r.Len() != 0 {
+		return nil, ErrInvalidVPTree
+	}
+
+	return t, nil
+}
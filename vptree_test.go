@@ -0,0 +1,183 @@
+/*
+Copyright 2018 Ahmed Zaher
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getBruteForceNeighbours(points []Point, query Point, metric Metric, radius float64) []Neighbour {
+	found := []Neighbour{}
+	for i, p := range points {
+		if p == nil {
+			continue
+		}
+		if d := metric(query, p); radius == 0 || d <= radius {
+			found = append(found, Neighbour{Index: i, Point: p, Distance: d})
+		}
+	}
+	sortNeighbours(found)
+	return found
+}
+
+func TestVPTree(t *testing.T) {
+
+	var x *vpTree
+	var vx VPTree = x
+
+	assert.Nil(t, vx.Points())
+	assert.Empty(t, vx.Nearest(NewPoint(0, 0), 1, NeighbourOptions{}))
+	assert.Empty(t, vx.Range(NewPoint(0, 0), 1))
+
+	vx = NewVPTree(nil, nil)
+	assert.Empty(t, vx.Nearest(NewPoint(0, 0), 1, NeighbourOptions{}))
+	assert.Empty(t, vx.Range(NewPoint(0, 0), 1))
+
+	points := []Point{NewPoint(0, 179.9), nil, NewPoint(0, -179.9), NewPoint(0, 170), NewPoint(90, 0), NewPoint(0, 179.9)}
+	vx = NewVPTree(points, nil)
+
+	assert.Equal(t, points, vx.Points())
+	assert.Empty(t, vx.Nearest(nil, 1, NeighbourOptions{}))
+	assert.Empty(t, vx.Nearest(NewPoint(0, 180), 0, NeighbourOptions{}))
+	assert.Empty(t, vx.Range(nil, 1))
+	assert.Empty(t, vx.Range(NewPoint(0, 180), -1))
+
+	found := vx.Nearest(NewPoint(0, -179.95), 3, NeighbourOptions{})
+	assert.Equal(t, []int{2, 0, 5}, []int{found[0].Index, found[1].Index, found[2].Index})
+	assert.Equal(t, GetDistance(NewPoint(0, -179.95), points[2]), found[0].Distance)
+	assert.Len(t, vx.Nearest(NewPoint(0, 180), 10, NeighbourOptions{MaxDistance: 100}), 3)
+	assert.Len(t, vx.Nearest(NewPoint(0, 180), 10, NeighbourOptions{}), 5)
+
+	found = vx.Range(NewPoint(0, -179.95), 100)
+	assert.Equal(t, []int{2, 0, 5}, []int{found[0].Index, found[1].Index, found[2].Index})
+}
+
+func TestVPTree_Random(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+	points := make([]Point, 2000)
+
+	for i := range points {
+		if i%100 != 99 {
+			points[i] = getRandomPoint(r)
+		}
+	}
+
+	// Duplicates are found as well.
+	points[1] = points[0]
+
+	calls := 0
+	counting := func(p1, p2 Point) float64 {
+		calls++
+		return GetEllipsoidDistance(p1, p2)
+	}
+
+	for _, metric := range []Metric{GetDistance, counting} {
+		x := NewVPTree(points, metric)
+
+		for i := 0; i < 100; i++ {
+			query := getRandomPoint(r)
+
+			if i == 0 {
+				query = points[0]
+			}
+
+			k := 1 + r.Intn(10)
+			expected := getBruteForceNeighbours(points, query, metric, 0)
+
+			calls = 0
+			assert.Equal(t, expected[:k], x.Nearest(query, k, NeighbourOptions{}))
+
+			if calls > 0 {
+				// The search doesn't measure the distance to all the points.
+				assert.True(t, calls < len(points)/2, "%d", calls)
+			}
+
+			radius := []float64{10, 100, 1000}[i%3]
+			expected = getBruteForceNeighbours(points, query, metric, radius)
+
+			assert.Equal(t, expected, x.Range(query, radius))
+
+			if len(expected) > k {
+				expected = expected[:k]
+			}
+
+			assert.Equal(t, expected, x.Nearest(query, k, NeighbourOptions{MaxDistance: radius}))
+		}
+	}
+}
+
+func TestVPTree_Marshal(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+	points := make([]Point, 500)
+
+	for i := range points {
+		if i%50 != 49 {
+			points[i] = getRandomPoint(r)
+		}
+	}
+
+	x := NewVPTree(points, GetEllipsoidDistance)
+	data, err := x.MarshalBinary()
+	assert.Nil(t, err)
+
+	y, err := UnmarshalVPTree(data, GetEllipsoidDistance)
+	assert.Nil(t, err)
+	assert.Equal(t, points, y.Points())
+	assert.Equal(t, x.(*vpTree).nodes, y.(*vpTree).nodes)
+
+	query := NewPoint(10, 20)
+	assert.Equal(t, x.Nearest(query, 5, NeighbourOptions{}), y.Nearest(query, 5, NeighbourOptions{}))
+
+	// The default metric is restored as well.
+	data, err = NewVPTree(nil, nil).MarshalBinary()
+	assert.Nil(t, err)
+
+	y, err = UnmarshalVPTree(data, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, y.Points())
+	assert.Empty(t, y.Nearest(query, 1, NeighbourOptions{}))
+
+	data, err = (*vpTree)(nil).MarshalBinary()
+	assert.Nil(t, err)
+	_, err = UnmarshalVPTree(data, nil)
+	assert.Nil(t, err)
+
+	valid, _ := x.MarshalBinary()
+
+	for _, data := range [][]byte{
+		nil,
+		[]byte("GVPT"),
+		[]byte("GVPT\x02"),
+		valid[:len(valid)-1],
+		append(append([]byte{}, valid...), 0),
+		append([]byte(vpTreeMagic), 0xff, 0xff, 0xff, 0xff),
+	} {
+		_, err := UnmarshalVPTree(data, nil)
+		assert.Equal(t, ErrInvalidVPTree, err)
+	}
+
+	// A node referring to itself as its child.
+	single, _ := NewVPTree([]Point{NewPoint(0, 0), NewPoint(1, 1)}, nil).MarshalBinary()
+	single[len(single)-4-4-24] = 0
+	_, err = UnmarshalVPTree(single, nil)
+	assert.Equal(t, ErrInvalidVPTree, err)
+}